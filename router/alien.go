@@ -3,6 +3,7 @@ package router
 import "fmt"
 import "path"
 import "sync"
+import "time"
 import "errors"
 import "strings"
 import "net/http"
@@ -96,6 +97,10 @@ func (node *Node) insert(pattern string, value *Route) error {
             level = level.branch(character, nil, NodeNormal)
         }
     }
+    if end := level.child(EOF); end != nil {
+        end.value = end.value.chain(value)
+        return nil
+    }
     level.branch(EOF, value, NodeEnd)
     return nil
 }
@@ -157,15 +162,33 @@ type RouteHandler = func(http.ResponseWriter, *http.Request)
 
 type Route struct {
     path       string
-    handler    RouteHandler
+    next       *Route
+    handler    http.Handler
+    matcher    []Matcher
+    limit      int64
+    overflow   http.Handler
+    timeout    time.Duration
+    expired    http.Handler
     middleware []Middleware
 }
 
+// Pattern returns the pattern route was registered with.
+func (route *Route) Pattern() string {
+    return route.path
+}
+
 func (route *Route) ServeHTTP(response http.ResponseWriter, request *http.Request) {
-    var base http.Handler = http.HandlerFunc(route.handler)
+    var base = route.handler
+    if route.limit > 0 {
+        base = route.limitBody(base)
+    }
     for _, middleware := range route.middleware {
         base = middleware(base)
     }
+    if route.timeout > 0 {
+        route.serveTimeout(base, response, request)
+        return
+    }
     base.ServeHTTP(response, request)
 }
 
@@ -179,44 +202,47 @@ func (route *Route) ServeHTTP(response http.ResponseWriter, request *http.Reques
 // this function captures the named params and theri coreesponding values, returning them in a comma separated  string of a key:value nature.
 // please see the tests for more details.
 func ParseParameter(match, pattern string) (result string, err error) {
-    if strings.Contains(pattern, ":") || strings.Contains(pattern, "*") {
-        p1 := strings.Split(match, "/")
-        p2 := strings.Split(pattern, "/")
-        s1 := len(p1)
-        s2 := len(p2)
-        if s1 < s2 {
-            err = errBadPattern
-            return
+    err = eachParameter(match, pattern, func(name, value string) {
+        if len(result) > 0 {
+            result = result + ","
         }
-        for k, v := range p2 {
-            if len(v) > 0 {
-                switch v[0] {
-                case ':':
-                    if len(result) == 0 {
-                        result = v[1:] + ":" + p1[k]
-                        continue
-                    }
-                    result = result + "," + v[1:] + ":" + p1[k]
-                case '*':
-                    name := "catch"
-                    if k != s2-1 {
-                        err = errBadPattern
-                        return
-                    }
-                    if len(v) > 1 {
-                        name = v[1:]
-                    }
-                    if len(result) == 0 {
-                        result = name + ":" + strings.Join(p1[k:], "/")
-                        return
-                    }
-                    result = result + "," + name + ":" + strings.Join(p1[k:], "/")
-                    return
+        result = result + name + ":" + value
+    })
+    return
+}
+
+// eachParameter calls found with every param of pattern and its value in
+// match, in the order they appear in pattern.
+func eachParameter(match, pattern string, found func(name, value string)) error {
+    if !strings.Contains(pattern, ":") && !strings.Contains(pattern, "*") {
+        return nil
+    }
+    p1 := strings.Split(match, "/")
+    p2 := strings.Split(pattern, "/")
+    s1 := len(p1)
+    s2 := len(p2)
+    if s1 < s2 {
+        return errBadPattern
+    }
+    for k, v := range p2 {
+        if len(v) > 0 {
+            switch v[0] {
+            case ':':
+                found(v[1:], p1[k])
+            case '*':
+                name := "catch"
+                if k != s2-1 {
+                    return errBadPattern
                 }
+                if len(v) > 1 {
+                    name = v[1:]
+                }
+                found(name, strings.Join(p1[k:], "/"))
+                return nil
             }
         }
     }
-    return
+    return nil
 }
 
 // Parameter 存储路由参数
@@ -241,6 +267,9 @@ func (parameter Parameter) Get(key string) string {
 
 // GetParameter 返回存储在请求中的路由参数
 func GetParameter(request *http.Request) Parameter {
+    if state := stateOf(request); state != nil {
+        return state.parameter
+    }
     value := request.Header.Get(headerName)
     if value != "" {
         parameter := make(Parameter)
@@ -251,25 +280,27 @@ func GetParameter(request *http.Request) Parameter {
 }
 
 type Router struct {
-    put     *Node
-    get     *Node
-    post    *Node
-    head    *Node
-    patch   *Node
-    trace   *Node
-    delete  *Node
-    connect *Node
-    options *Node
-}
-
-func (router *Router) addRoute(method, path string, handler RouteHandler, middleware ...Middleware) error {
-    value := &Route{
-        path:    path,
-        handler: handler,
-    }
-    if len(middleware) > 0 {
-        value.middleware = append(value.middleware, middleware...)
-    }
+    lock        Lock
+    hosts       []*hostRoute
+    global      []Middleware
+    renderer    []renderer
+    recovery    *Recovery
+    reporter    ErrorReporter
+    missing     []scopedHandler
+    unsupported []scopedHandler
+    put         *Node
+    get         *Node
+    post        *Node
+    head        *Node
+    patch       *Node
+    trace       *Node
+    delete      *Node
+    connect     *Node
+    options     *Node
+}
+
+func (router *Router) addRoute(method string, value *Route) error {
+    path := value.path
     switch method {
     case http.MethodGet:
         if router.get == nil {
@@ -362,6 +393,19 @@ func (router *Router) find(method, path string) (*Route, error) {
     return nil, ErrorRouteNotFound
 }
 
+// other reports whether path is registered for a method other than method.
+func (router *Router) other(method, path string) bool {
+    for _, value := range AllMethod {
+        if value == method {
+            continue
+        }
+        if _, exception := router.find(value, path); exception == nil {
+            return true
+        }
+    }
+    return false
+}
+
 // Mux is a http multiplexer that allows matching of http requests to the
 // registered http handlers.
 //
@@ -387,20 +431,35 @@ func (router *Router) find(method, path string) (*Route, error) {
 // If you dont specify a name in a catch all Route, then the default name "catch" will be used.
 type Mux struct {
     *Router
-    prefix     string
-    notFound   http.Handler
-    middleware []func(http.Handler) http.Handler
+    host          *hostRoute
+    prefix        string
+    matcher       []Matcher
+    notFound      http.Handler
+    unsupported   http.Handler
+    notAcceptable http.Handler
+    errorHandler  ErrorResponder
+    limit         int64
+    overflow      http.Handler
+    timeout       time.Duration
+    expired       http.Handler
+    middleware    []func(http.Handler) http.Handler
 }
 
 func New() *Mux {
     mux := &Mux{}
     mux.prefix = ""
     mux.Router = &Router{}
+    mux.errorHandler = DefaultErrorHandler
     mux.notFound = http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
         response.Header().Set("Content-Type", "text/html; charset=UTF-8")
         response.WriteHeader(http.StatusNotFound)
         response.Write([]byte("404 - Not Found"))
     })
+    mux.unsupported = http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        response.Header().Set("Content-Type", "text/html; charset=UTF-8")
+        response.WriteHeader(http.StatusUnsupportedMediaType)
+        response.Write([]byte("415 - Unsupported Media Type"))
+    })
     return mux
 }
 
@@ -431,9 +490,71 @@ func (mux *Mux) Options(pattern string, handler RouteHandler) error {
 func (mux *Mux) Connect(pattern string, handler RouteHandler) error {
     return mux.AddRoute(http.MethodConnect, pattern, handler)
 }
+func (mux *Mux) HandleGet(pattern string, handler http.Handler) error {
+    return mux.Handle(http.MethodGet, pattern, handler)
+}
+func (mux *Mux) HandlePut(pattern string, handler http.Handler) error {
+    return mux.Handle(http.MethodPut, pattern, handler)
+}
+func (mux *Mux) HandlePost(pattern string, handler http.Handler) error {
+    return mux.Handle(http.MethodPost, pattern, handler)
+}
+func (mux *Mux) HandleHead(pattern string, handler http.Handler) error {
+    return mux.Handle(http.MethodHead, pattern, handler)
+}
+func (mux *Mux) HandlePatch(pattern string, handler http.Handler) error {
+    return mux.Handle(http.MethodPatch, pattern, handler)
+}
+func (mux *Mux) HandleTrace(pattern string, handler http.Handler) error {
+    return mux.Handle(http.MethodTrace, pattern, handler)
+}
+func (mux *Mux) HandleDelete(pattern string, handler http.Handler) error {
+    return mux.Handle(http.MethodDelete, pattern, handler)
+}
+func (mux *Mux) HandleOptions(pattern string, handler http.Handler) error {
+    return mux.Handle(http.MethodOptions, pattern, handler)
+}
+func (mux *Mux) HandleConnect(pattern string, handler http.Handler) error {
+    return mux.Handle(http.MethodConnect, pattern, handler)
+}
 func (mux *Mux) AddRoute(method string, pattern string, handler RouteHandler) error {
-    pattern = path.Join(mux.prefix, pattern)
-    return mux.addRoute(method, pattern, handler, mux.middleware...)
+    return mux.Handle(method, pattern, http.HandlerFunc(handler))
+}
+
+// Handle registers handler for method and pattern. It is the http.Handler
+// counterpart of AddRoute.
+func (mux *Mux) Handle(method string, pattern string, handler http.Handler) error {
+    value := &Route{
+        path:     path.Join(mux.prefix, pattern),
+        handler:  handler,
+        limit:    mux.limit,
+        overflow: mux.overflow,
+        timeout:  mux.timeout,
+        expired:  mux.expired,
+    }
+    if len(mux.matcher) > 0 {
+        value.matcher = append(value.matcher, mux.matcher...)
+    }
+    if len(mux.middleware) > 0 {
+        value.middleware = append(value.middleware, mux.middleware...)
+    }
+    return mux.tree().addRoute(method, value)
+}
+
+// tree returns the Router the routes of mux are registered on.
+func (mux *Mux) tree() *Router {
+    if mux.host != nil {
+        return mux.host.router
+    }
+    return mux.Router
+}
+
+// derive returns a copy of mux sharing its Router.
+func (mux *Mux) derive() *Mux {
+    derived := *mux
+    derived.matcher = append([]Matcher(nil), mux.matcher...)
+    derived.middleware = append([]Middleware(nil), mux.middleware...)
+    return &derived
 }
 
 // HasRoute checks if a Route is present in the Mux.
@@ -442,7 +563,7 @@ func (mux *Mux) AddRoute(method string, pattern string, handler RouteHandler) er
 // It returns a boolean value indicating whether the Route is found or not, along with an error if any.
 func (mux *Mux) HasRoute(path, method string) (bool, error) {
     findRoute := func(method string) (bool, error) {
-        _, exception := mux.find(method, path)
+        _, exception := mux.tree().find(method, path)
         if exception == nil {
             return true, nil
         }
@@ -463,25 +584,165 @@ func (mux *Mux) HasRoute(path, method string) (bool, error) {
     return false, nil
 }
 
+// NotFoundHandler sets the handler answering requests no Route matches. Set
+// on a Group or Host it only answers the requests under that prefix or host,
+// the most specific scope wins.
 func (mux *Mux) NotFoundHandler(handler http.Handler) {
     mux.notFound = handler
+    mux.scope(&mux.Router.missing, handler)
+}
+
+// scopedHandler is a handler set on a Group, Host or Match result, it answers
+// the requests under its prefix and host.
+type scopedHandler struct {
+    host    *hostRoute
+    prefix  string
+    handler http.Handler
+}
+
+// scope sets handler for the prefix and host of mux in list.
+func (mux *Mux) scope(list *[]scopedHandler, handler http.Handler) {
+    mux.Router.lock.Lock()
+    defer mux.Router.lock.Unlock()
+    for index, value := range *list {
+        if value.host == mux.host && value.prefix == mux.prefix {
+            (*list)[index].handler = handler
+            return
+        }
+    }
+    *list = append(*list, scopedHandler{host: mux.host, prefix: mux.prefix, handler: handler})
+}
+
+// scoped returns the handler of list most specific to url requested on host,
+// fallback when none applies.
+func (mux *Mux) scoped(list []scopedHandler, fallback http.Handler, host *hostRoute, url string) http.Handler {
+    handler, length := fallback, -1
+    for _, value := range list {
+        if value.host != nil && value.host != host {
+            continue
+        }
+        if value.prefix != "" && value.prefix != "/" && url != value.prefix && !strings.HasPrefix(url, value.prefix+"/") {
+            continue
+        }
+        // a host scope is more specific than any prefix alone
+        current := len(value.prefix)
+        if value.host != nil {
+            current += len(url) + 1
+        }
+        if current > length {
+            handler, length = value.handler, current
+        }
+    }
+    return handler
+}
+
+// missingHandler returns the not found handler for url requested on host.
+func (mux *Mux) missingHandler(host *hostRoute, url string) http.Handler {
+    mux.Router.lock.RLock()
+    defer mux.Router.lock.RUnlock()
+    return mux.scoped(mux.Router.missing, mux.notFound, host, url)
+}
+
+// unsupportedHandler returns the unsupported media type handler for url
+// requested on host.
+func (mux *Mux) unsupportedHandler(host *hostRoute, url string) http.Handler {
+    mux.Router.lock.RLock()
+    defer mux.Router.lock.RUnlock()
+    return mux.scoped(mux.Router.unsupported, mux.unsupported, host, url)
 }
 
 // ServeHTTP implements http.Handler interface
 func (mux *Mux) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+    request, _ = withState(request)
+    var handler http.Handler = http.HandlerFunc(mux.dispatch)
+    for _, middleware := range mux.global {
+        handler = middleware(handler)
+    }
+    handler.ServeHTTP(response, request)
+}
+
+// dispatch serves request with the Route matching it.
+func (mux *Mux) dispatch(response http.ResponseWriter, request *http.Request) {
+    state := stateOf(request)
     url := path.Clean(request.URL.Path)
-    route, exception := mux.find(request.Method, url)
-    if exception != nil {
-        mux.notFound.ServeHTTP(response, request)
+    var route *Route
+    var status = http.StatusNotFound
+    var host *hostRoute
+    var parameter string
+    hosts := mux.matchHost(request.Host)
+    for _, value := range hosts {
+        var result int
+        if route, result = value.host.router.lookup(request, url); route != nil {
+            host, parameter = value.host, value.parameter
+            break
+        }
+        if status == http.StatusNotFound {
+            status = result
+        }
+    }
+    if route == nil {
+        var fallback int
+        if route, fallback = mux.Router.lookup(request, url); status == http.StatusNotFound {
+            status = fallback
+        }
+    }
+    if route == nil {
+        if len(hosts) > 0 {
+            host = hosts[0].host
+        }
+        state.outcome = status
+        if status == http.StatusNotFound && mux.otherMethod(hosts, request.Method, url) {
+            state.outcome = http.StatusMethodNotAllowed
+        }
+        if status == http.StatusUnsupportedMediaType {
+            mux.unsupportedHandler(host, url).ServeHTTP(response, request)
+            return
+        }
+        mux.missingHandler(host, url).ServeHTTP(response, request)
         return
     }
-    parameter, _ := ParseParameter(url, route.path)
+    // the values are kept in the state, the header form cannot carry ',' or ':'
+    var value Parameter
+    if parameter != "" {
+        value = make(Parameter)
+        value.Load(parameter)
+    }
+    eachParameter(url, route.path, func(name, data string) {
+        if value == nil {
+            value = make(Parameter)
+        }
+        value[name] = data
+        if parameter != "" {
+            parameter = parameter + ","
+        }
+        parameter = parameter + name + ":" + data
+    })
     if parameter != "" {
         request.Header.Set(headerName, parameter)
     }
+    state.route = route
+    state.parameter = value
+    if mux.recovery != nil {
+        mux.recovery.Middleware(route).ServeHTTP(response, request)
+        return
+    }
     route.ServeHTTP(response, request)
 }
 
+// otherMethod reports whether url is registered for another method than
+// method on the default routes or on one of hosts.
+func (mux *Mux) otherMethod(hosts []hostMatch, method, url string) bool {
+    if mux.Router.other(method, url) {
+        return true
+    }
+    for _, value := range hosts {
+        if value.host.router.other(method, url) {
+            return true
+        }
+    }
+    return false
+}
+
 // Group creates a path prefix group for pattern, all routes registered using
 // the returned Mux will only match if the request path starts with pattern. For
 // instance .
@@ -490,14 +751,13 @@ func (mux *Mux) ServeHTTP(response http.ResponseWriter, request *http.Request) {
 //   home.Get("/alone",myHandler)
 // will match
 //   /home/alone
+// The group keeps the host of mux, but pattern is not joined to the prefix of
+// mux.
 func (mux *Mux) Group(pattern string) *Mux {
-    return &Mux{
-        prefix:     pattern,
-        Router:     mux.Router,
-        middleware: mux.middleware,
-        notFound:   mux.notFound,
-    }
-
+    derived := mux.derive()
+    derived.prefix = pattern
+    derived.middleware = mux.middleware
+    return derived
 }
 
 // Use assigns midlewares to the current *Mux. All routes registered by the *Mux
@@ -507,3 +767,20 @@ func (mux *Mux) Use(middleware ...func(http.Handler) http.Handler) {
         mux.middleware = append(mux.middleware, middleware...)
     }
 }
+
+// UseGlobal assigns middlewares around the whole Mux. They run before the
+// request is routed, for unmatched requests too, and apply to every group. As
+// with Use the last assigned middleware runs first.
+func (mux *Mux) UseGlobal(middleware ...func(http.Handler) http.Handler) {
+    mux.Router.global = append(mux.Router.global, middleware...)
+}
+
+// With returns a Mux whose routes get middleware appended after the
+// middlewares inherited from mux. It attaches middlewares to a single route
+// without creating a Group. For instance
+//   m.With(requireAdmin, audit).Get("/admin", myHandler)
+func (mux *Mux) With(middleware ...func(http.Handler) http.Handler) *Mux {
+    derived := mux.derive()
+    derived.Use(middleware...)
+    return derived
+}
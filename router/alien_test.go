@@ -184,12 +184,29 @@ func TestMux_Group(t *testing.T) {
     m := New()
     g := m.Group("/hello")
     _ = g.Get("/world", func(_ http.ResponseWriter, _ *http.Request) {})
+    _ = m.Host("api.example.com").Group("/v1").Get("/users", func(_ http.ResponseWriter, _ *http.Request) {})
+    _ = g.Group("/v2").Get("/users", func(_ http.ResponseWriter, _ *http.Request) {})
 
-    req, _ := http.NewRequest("GET", "/hello/world", nil)
-    w := httptest.NewRecorder()
-    m.ServeHTTP(w, req)
-    if w.Code != http.StatusOK {
-        t.Errorf("expected %d got %d", http.StatusOK, w.Code)
+    sample := []struct {
+        host, path string
+        code       int
+    }{
+        {"", "/hello/world", http.StatusOK},
+        {"api.example.com", "/v1/users", http.StatusOK},
+        {"", "/v1/users", http.StatusNotFound},
+        {"", "/v2/users", http.StatusOK},
+        {"", "/hello/v2/users", http.StatusNotFound},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", v.path, nil)
+        if v.host != "" {
+            req.Host = v.host
+        }
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code {
+            t.Errorf("%s%s: expected %d got %d", v.host, v.path, v.code, w.Code)
+        }
     }
 }
 
@@ -254,3 +271,96 @@ func TestAlienMiddlewares(t *testing.T) {
         t.Errorf(" expected alien got %s ", w.Body)
     }
 }
+
+func TestMux_Handle(t *testing.T) {
+    h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        _, _ = w.Write([]byte(r.Method + GetParameter(r).Get("id")))
+    })
+    m := New()
+    registers := map[string]func(string, http.Handler) error{
+        "GET":     m.HandleGet,
+        "PUT":     m.HandlePut,
+        "POST":    m.HandlePost,
+        "HEAD":    m.HandleHead,
+        "PATCH":   m.HandlePatch,
+        "DELETE":  m.HandleDelete,
+        "OPTIONS": m.HandleOptions,
+        "CONNECT": m.HandleConnect,
+        "TRACE":   m.HandleTrace,
+    }
+    for method, register := range registers {
+        if err := register("/item/:id", h); err != nil {
+            t.Fatal(err)
+        }
+        req := httptest.NewRequest(method, "/item/7", nil)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Body.String() != method+"7" {
+            t.Errorf("expected %s7 got %s", method, w.Body)
+        }
+    }
+    if err := m.Handle("CRAP", "/item", h); err == nil {
+        t.Error("expected error")
+    }
+}
+
+func TestMux_With(t *testing.T) {
+    mark := func(name string) Middleware {
+        return func(in http.Handler) http.Handler {
+            return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                _, _ = w.Write([]byte(name))
+                in.ServeHTTP(w, r)
+            })
+        }
+    }
+    m := New()
+    m.Use(mark("group"))
+    _ = m.With(mark("admin")).Get("/admin", func(_ http.ResponseWriter, _ *http.Request) {})
+    _ = m.Get("/", func(_ http.ResponseWriter, _ *http.Request) {})
+    sample := []struct {
+        path, body string
+    }{
+        {"/admin", "admingroup"},
+        {"/", "group"},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", v.path, nil)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Body.String() != v.body {
+            t.Errorf("%s: expected %s got %s", v.path, v.body, w.Body)
+        }
+    }
+}
+
+func TestMux_NotFoundHandlerScope(t *testing.T) {
+    m := New()
+    scoped := func(body string) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            w.WriteHeader(http.StatusNotFound)
+            _, _ = w.Write([]byte(body))
+        })
+    }
+    m.Group("/api").NotFoundHandler(scoped("api"))
+    m.Group("/api/v2").NotFoundHandler(scoped("v2"))
+    m.Host("docs.example.com").NotFoundHandler(scoped("docs"))
+    sample := []struct {
+        host, path, body string
+    }{
+        {"example.com", "/api/missing", "api"},
+        {"example.com", "/api", "api"},
+        {"example.com", "/api/v2/missing", "v2"},
+        {"example.com", "/apis", "404 - Not Found"},
+        {"docs.example.com", "/api/missing", "docs"},
+        {"example.com", "/missing", "404 - Not Found"},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", v.path, nil)
+        req.Host = v.host
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != http.StatusNotFound || w.Body.String() != v.body {
+            t.Errorf("%s%s: expected %q got %d %q", v.host, v.path, v.body, w.Code, w.Body)
+        }
+    }
+}
@@ -0,0 +1,276 @@
+package router
+
+import "io"
+import "net"
+import "mime"
+import "sync"
+import "bufio"
+import "strings"
+import "net/http"
+import "compress/gzip"
+import "compress/zlib"
+
+// DefaultCompressType lists the media types a Compressor compresses by
+// default, a trailing /* matches a whole type.
+var DefaultCompressType = []string{
+    "text/*",
+    "application/json",
+    "application/javascript",
+    "application/xml",
+    "image/svg+xml",
+}
+
+// Compressor is a gzip and deflate compression middleware negotiating the
+// Accept-Encoding header. Assign it with Use on a Group to configure it per
+// Group, for instance
+//   api := m.Group("/api")
+//   api.Use((&Compressor{MinSize: 512}).Middleware)
+type Compressor struct {
+    // Level is the compression level, 0 picks the default level.
+    Level int
+    // MinSize is the smallest body compressed, it defaults to 1024 bytes.
+    MinSize int
+    // Type lists the media types compressed, it defaults to
+    // DefaultCompressType.
+    Type []string
+    once sync.Once
+    pool map[string]*sync.Pool
+}
+
+// encoder is the common part of gzip and zlib writers.
+type encoder interface {
+    io.WriteCloser
+    Flush() error
+    Reset(io.Writer)
+}
+
+// Middleware compresses the responses of next whose type is allowed and whose
+// body reaches MinSize. HEAD requests, 204 and 304 responses, partial content
+// and bodies carrying a Content-Encoding are left untouched. Flushing before
+// MinSize is reached sends the response uncompressed, so streams keep their
+// latency.
+func (compressor *Compressor) Middleware(next http.Handler) http.Handler {
+    compressor.once.Do(func() {
+        level := compressor.Level
+        if level == 0 {
+            level = gzip.DefaultCompression
+        }
+        compressor.pool = map[string]*sync.Pool{
+            "gzip": {New: func() any {
+                writer, _ := gzip.NewWriterLevel(io.Discard, level)
+                return writer
+            }},
+            "deflate": {New: func() any {
+                writer, _ := zlib.NewWriterLevel(io.Discard, level)
+                return writer
+            }},
+        }
+    })
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        if request.Method == http.MethodHead {
+            next.ServeHTTP(response, request)
+            return
+        }
+        writer := &compressWriter{ResponseWriter: response, compressor: compressor, coding: negotiateEncoding(request.Header.Get("Accept-Encoding"))}
+        completed := false
+        defer func() {
+            writer.close(completed)
+        }()
+        next.ServeHTTP(writer, request)
+        completed = true
+    })
+}
+
+func (compressor *Compressor) minSize() int {
+    if compressor.MinSize > 0 {
+        return compressor.MinSize
+    }
+    return 1024
+}
+
+// allow reports whether the Content-Type header value may be compressed.
+func (compressor *Compressor) allow(value string) bool {
+    media, _, exception := mime.ParseMediaType(value)
+    if exception != nil {
+        return false
+    }
+    list := compressor.Type
+    if list == nil {
+        list = DefaultCompressType
+    }
+    for _, item := range list {
+        if specificity(strings.ToLower(item), media) > 1 {
+            return true
+        }
+    }
+    return false
+}
+
+// negotiateEncoding returns the coding an Accept-Encoding header prefers
+// among gzip and deflate, an empty string when it accepts neither.
+func negotiateEncoding(header string) string {
+    var result string
+    var best float64
+    for _, coding := range []string{"gzip", "deflate"} {
+        quality, wildcard := -1.0, -1.0
+        for _, value := range parseAccept(header) {
+            switch value.media {
+            case coding:
+                quality = value.quality
+            case "*":
+                wildcard = value.quality
+            }
+        }
+        if quality < 0 {
+            quality = wildcard
+        }
+        if quality > best {
+            result, best = coding, quality
+        }
+    }
+    return result
+}
+
+// compressWriter buffers the start of a response until it knows whether to
+// compress it.
+type compressWriter struct {
+    http.ResponseWriter
+    compressor *Compressor
+    coding     string
+    status     int
+    buffer     []byte
+    decided    bool
+    encoder    encoder
+}
+
+func (writer *compressWriter) WriteHeader(status int) {
+    if status < http.StatusOK {
+        writer.ResponseWriter.WriteHeader(status)
+        return
+    }
+    if writer.status == 0 {
+        writer.status = status
+    }
+}
+
+func (writer *compressWriter) Write(data []byte) (int, error) {
+    if writer.status == 0 {
+        writer.status = http.StatusOK
+    }
+    if !writer.decided {
+        writer.buffer = append(writer.buffer, data...)
+        if len(writer.buffer) < writer.compressor.minSize() {
+            return len(data), nil
+        }
+        if exception := writer.decide(); exception != nil {
+            return 0, exception
+        }
+        return len(data), nil
+    }
+    if writer.encoder != nil {
+        return writer.encoder.Write(data)
+    }
+    return writer.ResponseWriter.Write(data)
+}
+
+// decide sends the header, compressed when the response allows it, followed
+// by the buffered body.
+func (writer *compressWriter) decide() error {
+    writer.decided = true
+    if writer.status == 0 {
+        writer.status = http.StatusOK
+    }
+    header := writer.ResponseWriter.Header()
+    if header.Get("Content-Type") == "" && len(writer.buffer) > 0 {
+        header.Set("Content-Type", http.DetectContentType(writer.buffer))
+    }
+    eligible := writer.status != http.StatusNoContent && writer.status != http.StatusNotModified &&
+        writer.status != http.StatusPartialContent && header.Get("Content-Encoding") == "" &&
+        header.Get("Content-Range") == "" && writer.compressor.allow(header.Get("Content-Type"))
+    if eligible {
+        vary(header, "Accept-Encoding")
+    }
+    if eligible && writer.coding != "" && len(writer.buffer) >= writer.compressor.minSize() {
+        header.Del("Content-Length")
+        header.Set("Content-Encoding", writer.coding)
+        if tag := header.Get("ETag"); tag != "" && !strings.HasPrefix(tag, "W/") {
+            header.Set("ETag", "W/"+tag)
+        }
+        writer.encoder = writer.compressor.pool[writer.coding].Get().(encoder)
+        writer.encoder.Reset(writer.ResponseWriter)
+    }
+    writer.ResponseWriter.WriteHeader(writer.status)
+    data := writer.buffer
+    writer.buffer = nil
+    if len(data) == 0 {
+        return nil
+    }
+    var exception error
+    if writer.encoder != nil {
+        _, exception = writer.encoder.Write(data)
+    } else {
+        _, exception = writer.ResponseWriter.Write(data)
+    }
+    return exception
+}
+
+// close ends the response once the handler returned. When it panicked
+// instead, completed is false and the buffered start of the response is
+// dropped so that Recovery may still answer.
+func (writer *compressWriter) close(completed bool) {
+    if !writer.decided {
+        if !completed || writer.status == 0 && len(writer.buffer) == 0 {
+            return
+        }
+        writer.decide()
+    }
+    if writer.encoder != nil {
+        if completed {
+            writer.encoder.Close()
+        }
+        writer.encoder.Reset(io.Discard)
+        writer.compressor.pool[writer.coding].Put(writer.encoder)
+        writer.encoder = nil
+    }
+}
+
+func (writer *compressWriter) Flush() {
+    writer.FlushError()
+}
+
+// FlushError sends what is buffered and flushes the wrapped
+// http.ResponseWriter, reporting when it cannot be flushed.
+func (writer *compressWriter) FlushError() error {
+    if !writer.decided {
+        if exception := writer.decide(); exception != nil {
+            return exception
+        }
+    }
+    if writer.encoder != nil {
+        if exception := writer.encoder.Flush(); exception != nil {
+            return exception
+        }
+    }
+    return http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+func (writer *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    return http.NewResponseController(writer.ResponseWriter).Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the wrapped http.ResponseWriter.
+func (writer *compressWriter) Unwrap() http.ResponseWriter {
+    return writer.ResponseWriter
+}
+
+// vary adds value to the Vary header unless it is listed already.
+func vary(header http.Header, value string) {
+    for _, line := range header.Values("Vary") {
+        for _, item := range strings.Split(line, ",") {
+            if strings.EqualFold(strings.TrimSpace(item), value) || strings.TrimSpace(item) == "*" {
+                return
+            }
+        }
+    }
+    header.Add("Vary", value)
+}
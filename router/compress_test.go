@@ -0,0 +1,134 @@
+package router
+
+import (
+    "compress/gzip"
+    "compress/zlib"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestCompressor(t *testing.T) {
+    large := strings.Repeat("compress me ", 200)
+    m := New()
+    api := m.Group("/api")
+    api.Use((&Compressor{MinSize: 64}).Middleware)
+    _ = api.Get("/text", func(w http.ResponseWriter, r *http.Request) {
+        _ = Text(w, http.StatusOK, large)
+    })
+    _ = api.Get("/small", func(w http.ResponseWriter, r *http.Request) {
+        _ = Text(w, http.StatusOK, "small")
+    })
+    _ = api.Get("/png", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "image/png")
+        _, _ = w.Write([]byte(large))
+    })
+    _ = api.Get("/encoded", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/plain")
+        w.Header().Set("Content-Encoding", "br")
+        _, _ = w.Write([]byte(large))
+    })
+    _ = api.Get("/empty", func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusNoContent)
+    })
+    _ = api.Get("/cached", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/plain")
+        w.WriteHeader(http.StatusNotModified)
+    })
+    _ = api.Head("/text", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/plain")
+    })
+    _ = m.Get("/plain", func(w http.ResponseWriter, r *http.Request) {
+        _ = Text(w, http.StatusOK, large)
+    })
+
+    sample := []struct {
+        method, path, accept string
+        encoding, vary       string
+        code                 int
+    }{
+        {"GET", "/api/text", "gzip, deflate", "gzip", "Accept-Encoding", http.StatusOK},
+        {"GET", "/api/text", "gzip;q=0.5, deflate", "deflate", "Accept-Encoding", http.StatusOK},
+        {"GET", "/api/text", "br, *;q=0.1", "gzip", "Accept-Encoding", http.StatusOK},
+        {"GET", "/api/text", "gzip;q=0, identity", "", "Accept-Encoding", http.StatusOK},
+        {"GET", "/api/text", "", "", "Accept-Encoding", http.StatusOK},
+        {"GET", "/api/small", "gzip", "", "Accept-Encoding", http.StatusOK},
+        {"GET", "/api/png", "gzip", "", "", http.StatusOK},
+        {"GET", "/api/encoded", "gzip", "br", "", http.StatusOK},
+        {"GET", "/api/empty", "gzip", "", "", http.StatusNoContent},
+        {"GET", "/api/cached", "gzip", "", "", http.StatusNotModified},
+        {"HEAD", "/api/text", "gzip", "", "", http.StatusOK},
+        {"GET", "/plain", "gzip", "", "", http.StatusOK},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest(v.method, v.path, nil)
+        req.Header.Set("Accept-Encoding", v.accept)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code || w.Header().Get("Content-Encoding") != v.encoding || w.Header().Get("Vary") != v.vary {
+            t.Errorf("%s %s %q: expected %d %q %q got %d %q %q", v.method, v.path, v.accept, v.code, v.encoding, v.vary, w.Code, w.Header().Get("Content-Encoding"), w.Header().Get("Vary"))
+            continue
+        }
+        var reader io.Reader = w.Body
+        switch v.encoding {
+        case "gzip":
+            reader, _ = gzip.NewReader(w.Body)
+        case "deflate":
+            reader, _ = zlib.NewReader(w.Body)
+        default:
+            continue
+        }
+        if w.Header().Get("Content-Length") != "" {
+            t.Errorf("%s %q: unexpected Content-Length %s", v.path, v.accept, w.Header().Get("Content-Length"))
+        }
+        if data, err := io.ReadAll(reader); err != nil || string(data) != large {
+            t.Errorf("%s %q: body does not decompress %v", v.path, v.accept, err)
+        }
+    }
+}
+
+func TestCompressor_Flush(t *testing.T) {
+    m := New()
+    m.Use((&Compressor{}).Middleware)
+    flushed := make(chan struct{})
+    _ = m.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "text/event-stream")
+        _, _ = w.Write([]byte("data: hello\n\n"))
+        http.NewResponseController(w).Flush()
+        <-flushed
+    })
+    server := httptest.NewServer(m)
+    defer server.Close()
+    req, _ := http.NewRequest("GET", server.URL+"/events", nil)
+    req.Header.Set("Accept-Encoding", "gzip")
+    res, err := http.DefaultTransport.RoundTrip(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer res.Body.Close()
+    data := make([]byte, 13)
+    _, err = io.ReadFull(res.Body, data)
+    close(flushed)
+    if err != nil || string(data) != "data: hello\n\n" || res.Header.Get("Content-Encoding") != "" {
+        t.Errorf("expected the flushed event got %q %v %q", data, err, res.Header.Get("Content-Encoding"))
+    }
+}
+
+func TestCompressor_Recover(t *testing.T) {
+    m := New()
+    m.Recover(&Recovery{Reporter: func(_ *http.Request, _ *Panic) {}})
+    m.Use((&Compressor{}).Middleware)
+    _ = m.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+        _, _ = w.Write([]byte("partial"))
+        panic("boom")
+    })
+    req := httptest.NewRequest("GET", "/panic", nil)
+    req.Header.Set("Accept-Encoding", "gzip")
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, req)
+    if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "partial") {
+        t.Errorf("expected a clean %d got %d %q", http.StatusInternalServerError, w.Code, w.Body.String())
+    }
+}
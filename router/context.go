@@ -0,0 +1,42 @@
+package router
+
+import "context"
+import "net/http"
+
+// contextKey is the type of the values this package stores in a request context.
+type contextKey int
+
+const (
+    keyOriginalPath contextKey = iota
+    keyState
+    keyRequestID
+)
+
+// routeState records what the Mux found for a request.
+type routeState struct {
+    route     *Route
+    parameter Parameter
+    outcome   int
+    requestID string
+}
+
+// withState returns request carrying a fresh routeState.
+func withState(request *http.Request) (*http.Request, *routeState) {
+    state := &routeState{}
+    return request.WithContext(context.WithValue(request.Context(), keyState, state)), state
+}
+
+// stateOf returns the routeState of request, nil outside of a Mux.
+func stateOf(request *http.Request) *routeState {
+    state, _ := request.Context().Value(keyState).(*routeState)
+    return state
+}
+
+// CurrentRoute returns the Route the Mux matched for request, nil when none
+// matched yet.
+func CurrentRoute(request *http.Request) *Route {
+    if state := stateOf(request); state != nil {
+        return state.route
+    }
+    return nil
+}
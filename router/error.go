@@ -0,0 +1,183 @@
+package router
+
+import "log"
+import "errors"
+import "strconv"
+import "net/http"
+import "encoding/json"
+
+// ErrorRouteHandler is a RouteHandler that returns the error it failed with
+// instead of writing the error response itself.
+type ErrorRouteHandler = func(http.ResponseWriter, *http.Request) error
+
+// ErrorResponder writes the response for an error returned by an ErrorRouteHandler.
+type ErrorResponder = func(http.ResponseWriter, *http.Request, error)
+
+// ErrorReporter receives every error returned by an ErrorRouteHandler with
+// the status it is answered with.
+type ErrorReporter = func(request *http.Request, status int, err error)
+
+// HTTPError is an error carrying the http status it should be answered with.
+type HTTPError struct {
+    Status  int
+    Message string
+    Err     error
+}
+
+// NewHTTPError returns an HTTPError answered with status and message.
+func NewHTTPError(status int, message string) *HTTPError {
+    return &HTTPError{Status: status, Message: message}
+}
+
+// WrapHTTPError returns an HTTPError answered with status that wraps err. The
+// message of err is not exposed to the client.
+func WrapHTTPError(status int, err error) *HTTPError {
+    return &HTTPError{Status: status, Message: http.StatusText(status), Err: err}
+}
+
+func (exception *HTTPError) Error() string {
+    if exception.Err != nil {
+        return strconv.Itoa(exception.Status) + " " + exception.Message + ": " + exception.Err.Error()
+    }
+    return strconv.Itoa(exception.Status) + " " + exception.Message
+}
+
+func (exception *HTTPError) Unwrap() error {
+    return exception.Err
+}
+
+// errorStatus returns the http status and client facing message for err.
+func errorStatus(err error) (int, string) {
+    var exception *HTTPError
+    if errors.As(err, &exception) {
+        message := exception.Message
+        if message == "" {
+            message = http.StatusText(exception.Status)
+        }
+        return exception.Status, message
+    }
+    var validation ValidationError
+    if errors.As(err, &validation) {
+        return http.StatusUnprocessableEntity, err.Error()
+    }
+    var field *FieldError
+    var bind BindError
+    if errors.As(err, &field) || errors.As(err, &bind) {
+        return http.StatusBadRequest, err.Error()
+    }
+    var overflow *http.MaxBytesError
+    if errors.As(err, &overflow) {
+        return http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge)
+    }
+    return http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+}
+
+// DefaultErrorHandler answers with the status of err and a plain text body,
+// errors of unknown kind become a 500.
+func DefaultErrorHandler(response http.ResponseWriter, _ *http.Request, err error) {
+    status, message := errorStatus(err)
+    response.Header().Set("Content-Type", "text/plain; charset=utf-8")
+    response.Header().Set("X-Content-Type-Options", "nosniff")
+    response.WriteHeader(status)
+    response.Write([]byte(strconv.Itoa(status) + " - " + message))
+}
+
+// Problem is the application/problem+json document of RFC 9457.
+type Problem struct {
+    Type     string `json:"type"`
+    Title    string `json:"title"`
+    Status   int    `json:"status"`
+    Detail   string `json:"detail,omitempty"`
+    Instance string `json:"instance,omitempty"`
+}
+
+// problemDocument is a Problem extended with the fields that failed.
+type problemDocument struct {
+    Problem
+    Errors []*FieldError `json:"errors,omitempty"`
+}
+
+// ProblemJSON is an ErrorResponder rendering err as application/problem+json.
+// Binding and validation failures list their fields under "errors". It is
+// meant for api groups
+//   api := m.Group("/api")
+//   api.ErrorHandler(ProblemJSON)
+func ProblemJSON(response http.ResponseWriter, request *http.Request, err error) {
+    status, message := errorStatus(err)
+    problem := Problem{
+        Type:     "about:blank",
+        Title:    http.StatusText(status),
+        Status:   status,
+        Instance: request.URL.Path,
+    }
+    if message != problem.Title {
+        problem.Detail = message
+    }
+    document := problemDocument{Problem: problem}
+    var validation ValidationError
+    var bind BindError
+    var field *FieldError
+    switch {
+    case errors.As(err, &validation):
+        document.Errors = validation
+    case errors.As(err, &bind):
+        document.Errors = bind
+    case errors.As(err, &field):
+        document.Errors = []*FieldError{field}
+    }
+    response.Header().Set("Content-Type", "application/problem+json")
+    response.WriteHeader(status)
+    json.NewEncoder(response).Encode(document)
+}
+
+// ErrorHandler sets the ErrorResponder used by the error returning routes of
+// mux. Groups inherit the ErrorResponder of their parent when created.
+func (mux *Mux) ErrorHandler(handler ErrorResponder) {
+    mux.errorHandler = handler
+}
+
+// Report sets the ErrorReporter of every error returning route, nil restores
+// the default which logs the errors answered with a 5xx.
+func (mux *Mux) Report(report ErrorReporter) {
+    mux.Router.lock.Lock()
+    defer mux.Router.lock.Unlock()
+    mux.Router.reporter = report
+}
+
+// Catch adapts handler to an http.Handler passing the errors it returns to
+// the ErrorReporter and the ErrorResponder of mux.
+func (mux *Mux) Catch(handler ErrorRouteHandler) http.Handler {
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        if exception := handler(response, request); exception != nil {
+            mux.Router.lock.RLock()
+            report := mux.Router.reporter
+            mux.Router.lock.RUnlock()
+            if report == nil {
+                report = reportError
+            }
+            status, _ := errorStatus(exception)
+            report(request, status, exception)
+            responder := mux.errorHandler
+            if responder == nil {
+                responder = DefaultErrorHandler
+            }
+            responder(response, request, exception)
+        }
+    })
+}
+
+func reportError(request *http.Request, status int, err error) {
+    if status < http.StatusInternalServerError {
+        return
+    }
+    pattern := ""
+    if route := CurrentRoute(request); route != nil {
+        pattern = route.Pattern()
+    }
+    log.Printf("router: error serving %s %s (%s) [%s]: %d %v", request.Method, request.URL.Path, pattern, RequestID(request), status, err)
+}
+
+// HandleError registers the error returning handler for method and pattern.
+func (mux *Mux) HandleError(method string, pattern string, handler ErrorRouteHandler) error {
+    return mux.Handle(method, pattern, mux.Catch(handler))
+}
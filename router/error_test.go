@@ -0,0 +1,103 @@
+package router
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "strings"
+    "testing"
+)
+
+func TestMux_HandleError(t *testing.T) {
+    fail := func(err error) ErrorRouteHandler {
+        return func(w http.ResponseWriter, _ *http.Request) error {
+            if err == nil {
+                _, _ = w.Write([]byte("ok"))
+            }
+            return err
+        }
+    }
+    m := New()
+    _ = m.HandleError("GET", "/ok", fail(nil))
+    _ = m.HandleError("GET", "/missing", fail(NewHTTPError(http.StatusNotFound, "no such user")))
+    _ = m.HandleError("GET", "/wrapped", fail(fmt.Errorf("load: %w", NewHTTPError(http.StatusConflict, "taken"))))
+    _ = m.HandleError("GET", "/boom", fail(errors.New("database is down")))
+
+    sample := []struct {
+        path, body string
+        code       int
+    }{
+        {"/ok", "ok", http.StatusOK},
+        {"/missing", "404 - no such user", http.StatusNotFound},
+        {"/wrapped", "409 - taken", http.StatusConflict},
+        {"/boom", "500 - Internal Server Error", http.StatusInternalServerError},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", v.path, nil)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code || w.Body.String() != v.body {
+            t.Errorf("%s: expected %d %s got %d %s", v.path, v.code, v.body, w.Code, w.Body)
+        }
+    }
+}
+
+func TestMux_Report(t *testing.T) {
+    m := New()
+    _ = m.HandleError("GET", "/missing", func(_ http.ResponseWriter, _ *http.Request) error {
+        return NewHTTPError(http.StatusNotFound, "no such user")
+    })
+    _ = m.Group("/api").HandleError("GET", "/boom", func(_ http.ResponseWriter, _ *http.Request) error {
+        return errors.New("database is down")
+    })
+
+    var buffer bytes.Buffer
+    log.SetOutput(&buffer)
+    defer log.SetOutput(os.Stderr)
+    for _, path := range []string{"/missing", "/api/boom"} {
+        m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+    }
+    if value := buffer.String(); !strings.Contains(value, "GET /api/boom (/api/boom)") || !strings.Contains(value, "500 database is down") || strings.Contains(value, "no such user") {
+        t.Errorf("expected only the 500 to be logged got %q", value)
+    }
+
+    var reported []string
+    m.Report(func(r *http.Request, status int, err error) {
+        reported = append(reported, fmt.Sprint(r.URL.Path, " ", status, " ", err))
+    })
+    for _, path := range []string{"/missing", "/api/boom"} {
+        m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+    }
+    expected := []string{"/missing 404 404 no such user", "/api/boom 500 database is down"}
+    if fmt.Sprint(reported) != fmt.Sprint(expected) {
+        t.Errorf("expected %v got %v", expected, reported)
+    }
+}
+
+func TestProblemJSON(t *testing.T) {
+    m := New()
+    api := m.Group("/api")
+    api.ErrorHandler(ProblemJSON)
+    _ = api.HandleError("GET", "/user", func(_ http.ResponseWriter, _ *http.Request) error {
+        return WrapHTTPError(http.StatusBadGateway, errors.New("upstream"))
+    })
+    req := httptest.NewRequest("GET", "/api/user", nil)
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, req)
+    if w.Header().Get("Content-Type") != "application/problem+json" {
+        t.Errorf("expected problem+json got %s", w.Header().Get("Content-Type"))
+    }
+    var problem Problem
+    if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+        t.Fatal(err)
+    }
+    expect := Problem{Type: "about:blank", Title: "Bad Gateway", Status: http.StatusBadGateway, Instance: "/api/user"}
+    if problem != expect {
+        t.Errorf("expected %+v got %+v", expect, problem)
+    }
+}
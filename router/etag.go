@@ -0,0 +1,163 @@
+package router
+
+import "net"
+import "bufio"
+import "strconv"
+import "strings"
+import "net/http"
+import "crypto/sha256"
+import "encoding/hex"
+
+// ETagger answers conditional GET and HEAD requests of dynamic responses. It buffers
+// the response to compute its ETag, so assign it to the routes that need it
+// with With, for instance
+//   tagger := &ETagger{MaxSize: 64 << 10}
+//   m.With(tagger.Middleware).Get("/users", users)
+type ETagger struct {
+    // MaxSize is the largest body buffered, it defaults to 1 MiB. Larger
+    // responses are sent as they are written, without ETag.
+    MaxSize int
+}
+
+// Middleware sets a strong ETag on the 200 responses of GET and HEAD requests
+// that carry none, a sha256 of the body, and answers 304 without body when it
+// matches If-None-Match. Responses flushed by next, or outgrowing MaxSize,
+// are streamed untouched, as are empty responses whose Content-Length was set
+// by next, such as HEAD answers that leave the body out.
+func (tagger *ETagger) Middleware(next http.Handler) http.Handler {
+    limit := tagger.MaxSize
+    if limit <= 0 {
+        limit = 1 << 20
+    }
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        if request.Method != http.MethodGet && request.Method != http.MethodHead {
+            next.ServeHTTP(response, request)
+            return
+        }
+        writer := &etagWriter{ResponseWriter: response, limit: limit}
+        next.ServeHTTP(writer, request)
+        if !writer.streaming {
+            writer.finish(request)
+        }
+    })
+}
+
+// etagWriter buffers a response until it is complete, or streams it once it
+// is flushed or outgrows limit.
+type etagWriter struct {
+    http.ResponseWriter
+    limit     int
+    status    int
+    buffer    []byte
+    streaming bool
+}
+
+func (writer *etagWriter) WriteHeader(status int) {
+    if writer.streaming || status < http.StatusOK {
+        writer.ResponseWriter.WriteHeader(status)
+        return
+    }
+    if writer.status == 0 {
+        writer.status = status
+    }
+}
+
+func (writer *etagWriter) Write(data []byte) (int, error) {
+    if writer.status == 0 {
+        writer.status = http.StatusOK
+    }
+    if writer.streaming {
+        return writer.ResponseWriter.Write(data)
+    }
+    if len(writer.buffer)+len(data) <= writer.limit {
+        writer.buffer = append(writer.buffer, data...)
+        return len(data), nil
+    }
+    if exception := writer.stream(); exception != nil {
+        return 0, exception
+    }
+    return writer.ResponseWriter.Write(data)
+}
+
+// stream sends what is buffered and lets the rest of the response through.
+func (writer *etagWriter) stream() error {
+    writer.streaming = true
+    if writer.status == 0 {
+        writer.status = http.StatusOK
+    }
+    writer.ResponseWriter.WriteHeader(writer.status)
+    data := writer.buffer
+    writer.buffer = nil
+    if len(data) == 0 {
+        return nil
+    }
+    _, exception := writer.ResponseWriter.Write(data)
+    return exception
+}
+
+// finish sends the buffered response, or a 304 when request already holds it.
+func (writer *etagWriter) finish(request *http.Request) {
+    if writer.status == 0 && len(writer.buffer) == 0 {
+        return
+    }
+    if writer.status == 0 {
+        writer.status = http.StatusOK
+    }
+    header := writer.ResponseWriter.Header()
+    if writer.status == http.StatusOK && (len(writer.buffer) > 0 || header.Get("Content-Length") == "") {
+        tag := header.Get("ETag")
+        if tag == "" {
+            digest := sha256.Sum256(writer.buffer)
+            tag = `"` + hex.EncodeToString(digest[:16]) + `"`
+            header.Set("ETag", tag)
+        }
+        if matchETag(request.Header.Get("If-None-Match"), tag) {
+            header.Del("Content-Type")
+            header.Del("Content-Length")
+            writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+            return
+        }
+        if header.Get("Content-Length") == "" {
+            header.Set("Content-Length", strconv.Itoa(len(writer.buffer)))
+        }
+    }
+    writer.ResponseWriter.WriteHeader(writer.status)
+    writer.ResponseWriter.Write(writer.buffer)
+}
+
+func (writer *etagWriter) Flush() {
+    writer.FlushError()
+}
+
+// FlushError streams what is buffered and flushes the wrapped
+// http.ResponseWriter, reporting when it cannot be flushed.
+func (writer *etagWriter) FlushError() error {
+    if !writer.streaming {
+        if exception := writer.stream(); exception != nil {
+            return exception
+        }
+    }
+    return http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+func (writer *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    return http.NewResponseController(writer.ResponseWriter).Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the wrapped http.ResponseWriter.
+func (writer *etagWriter) Unwrap() http.ResponseWriter {
+    return writer.ResponseWriter
+}
+
+// matchETag reports whether an If-None-Match header matches tag, using the
+// weak comparison RFC 9110 prescribes for it.
+func matchETag(header, tag string) bool {
+    tag = strings.TrimPrefix(tag, "W/")
+    for _, item := range strings.Split(header, ",") {
+        item = strings.TrimSpace(item)
+        if item == "*" || item != "" && strings.TrimPrefix(item, "W/") == tag {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "testing/fstest"
+    "time"
+)
+
+func TestETagger(t *testing.T) {
+    m := New()
+    tagged := m.With((&ETagger{MaxSize: 64}).Middleware)
+    _ = tagged.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+        _ = JSON(w, http.StatusOK, []string{"alien", "tiny"})
+    })
+    _ = tagged.Get("/large", func(w http.ResponseWriter, r *http.Request) {
+        _, _ = w.Write([]byte(strings.Repeat("a", 100)))
+    })
+    _ = tagged.Get("/stream", func(w http.ResponseWriter, r *http.Request) {
+        _, _ = w.Write([]byte("chunk"))
+        http.NewResponseController(w).Flush()
+    })
+    _ = tagged.Get("/created", func(w http.ResponseWriter, r *http.Request) {
+        _ = Text(w, http.StatusCreated, "created")
+    })
+    _ = tagged.Get("/versioned", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("ETag", `W/"v1"`)
+        _ = Text(w, http.StatusOK, "versioned")
+    })
+    _ = m.Get("/plain", func(w http.ResponseWriter, r *http.Request) {
+        _ = Text(w, http.StatusOK, "plain")
+    })
+
+    req := httptest.NewRequest("GET", "/users", nil)
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, req)
+    tag := w.Header().Get("ETag")
+    if w.Code != http.StatusOK || len(tag) != 34 || w.Body.String() != "[\"alien\",\"tiny\"]\n" {
+        t.Fatalf("unexpected response %d %q %q", w.Code, tag, w.Body)
+    }
+
+    sample := []struct {
+        path, match string
+        code        int
+        etag        bool
+        body        string
+    }{
+        {"/users", tag, http.StatusNotModified, true, ""},
+        {"/users", `"other", W/` + tag, http.StatusNotModified, true, ""},
+        {"/users", "*", http.StatusNotModified, true, ""},
+        {"/users", `"other"`, http.StatusOK, true, "[\"alien\",\"tiny\"]\n"},
+        {"/large", "*", http.StatusOK, false, strings.Repeat("a", 100)},
+        {"/stream", "*", http.StatusOK, false, "chunk"},
+        {"/created", "*", http.StatusCreated, false, "created"},
+        {"/versioned", `"v1"`, http.StatusNotModified, true, ""},
+        {"/plain", "*", http.StatusOK, false, "plain"},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", v.path, nil)
+        req.Header.Set("If-None-Match", v.match)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code || (w.Header().Get("ETag") != "") != v.etag || w.Body.String() != v.body {
+            t.Errorf("%s %s: expected %d %v %q got %d %q %q", v.path, v.match, v.code, v.etag, v.body, w.Code, w.Header().Get("ETag"), w.Body)
+        }
+    }
+}
+
+func TestETagger_Head(t *testing.T) {
+    m := New()
+    fsys := fstest.MapFS{"app.js": {Data: []byte("console.log(1)"), ModTime: time.Now()}}
+    _ = m.With((&ETagger{}).Middleware).Static("/assets", fsys)
+    _ = m.With((&ETagger{}).Middleware).Head("/sized", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Length", "42")
+    })
+    _ = m.With((&ETagger{}).Middleware).Get("/users", func(w http.ResponseWriter, r *http.Request) {
+        _ = Text(w, http.StatusOK, "users")
+    })
+    _ = m.With((&ETagger{}).Middleware).Head("/users", func(w http.ResponseWriter, r *http.Request) {
+        _ = Text(w, http.StatusOK, "users")
+    })
+    sample := []struct {
+        path, length string
+    }{
+        {"/assets/app.js", "14"},
+        {"/sized", "42"},
+    }
+    for _, v := range sample {
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, httptest.NewRequest("HEAD", v.path, nil))
+        if w.Code != http.StatusOK || w.Header().Get("Content-Length") != v.length {
+            t.Errorf("%s: expected %d with Content-Length %s got %d %q", v.path, http.StatusOK, v.length, w.Code, w.Header().Get("Content-Length"))
+        }
+    }
+
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+    tag := w.Header().Get("ETag")
+    w = httptest.NewRecorder()
+    m.ServeHTTP(w, httptest.NewRequest("HEAD", "/users", nil))
+    if tag == "" || w.Header().Get("ETag") != tag {
+        t.Fatalf("expected HEAD to carry the ETag %q of GET got %q", tag, w.Header().Get("ETag"))
+    }
+    req := httptest.NewRequest("HEAD", "/users", nil)
+    req.Header.Set("If-None-Match", tag)
+    w = httptest.NewRecorder()
+    m.ServeHTTP(w, req)
+    if w.Code != http.StatusNotModified {
+        t.Errorf("expected %d got %d", http.StatusNotModified, w.Code)
+    }
+}
@@ -0,0 +1,128 @@
+package router
+
+import "net"
+import "strings"
+
+// hostRoute holds the routes registered for a single host pattern.
+type hostRoute struct {
+    pattern string
+    label   []string
+    // exact counts the labels without placeholder, more exact patterns are
+    // tried first.
+    exact  int
+    router *Router
+}
+
+func newHostRoute(pattern string) *hostRoute {
+    pattern = strings.TrimSuffix(strings.ToLower(pattern), ".")
+    host := &hostRoute{
+        pattern: pattern,
+        label:   strings.Split(pattern, "."),
+        router:  &Router{},
+    }
+    for _, value := range host.label {
+        if !placeholder(value) {
+            host.exact++
+        }
+    }
+    return host
+}
+
+// placeholder reports whether a pattern label is of the form {name}.
+func placeholder(label string) bool {
+    return len(label) > 2 && label[0] == '{' && label[len(label)-1] == '}'
+}
+
+// match reports whether host satisfies the pattern, returning the captured
+// placeholders in the same key:value form used by ParseParameter.
+func (host *hostRoute) match(value string) (string, bool) {
+    label := strings.Split(value, ".")
+    if len(label) != len(host.label) {
+        return "", false
+    }
+    var result string
+    for index, expect := range host.label {
+        if placeholder(expect) {
+            if label[index] == "" {
+                return "", false
+            }
+            if len(result) > 0 {
+                result = result + ","
+            }
+            result = result + expect[1:len(expect)-1] + ":" + label[index]
+            continue
+        }
+        if expect != label[index] {
+            return "", false
+        }
+    }
+    return result, true
+}
+
+// requestHost returns the lower cased host of request without port.
+func requestHost(value string) string {
+    if host, _, exception := net.SplitHostPort(value); exception == nil {
+        value = host
+    }
+    return strings.TrimSuffix(strings.ToLower(value), ".")
+}
+
+// Host returns a Mux whose routes only match requests sent to host. Labels of
+// the form {name} match a single host label and are exposed through
+// GetParameter together with the path parameters.
+//
+// For instance
+//   m := New()
+//   tenant := m.Host("{tenant}.example.com")
+//   tenant.Get("/users", myHandler)
+// will match http://api.example.com/users with the parameter tenant:api.
+//
+// Hosts with more exact labels are tried first, so api.example.com wins over
+// {tenant}.example.com whatever their registration order. Requests whose path
+// is not registered on a matching host are tried on the next matching host,
+// then fall back to the routes registered without a host.
+func (mux *Mux) Host(pattern string) *Mux {
+    host := newHostRoute(pattern)
+    mux.Router.lock.Lock()
+    defer mux.Router.lock.Unlock()
+    var found bool
+    for _, value := range mux.Router.hosts {
+        if value.pattern == host.pattern {
+            host, found = value, true
+            break
+        }
+    }
+    if !found {
+        index := len(mux.Router.hosts)
+        for index > 0 && mux.Router.hosts[index-1].exact < host.exact {
+            index--
+        }
+        mux.Router.hosts = append(mux.Router.hosts[:index], append([]*hostRoute{host}, mux.Router.hosts[index:]...)...)
+    }
+    derived := mux.derive()
+    derived.host = host
+    return derived
+}
+
+// hostMatch is a registered host matching a request, with its placeholders.
+type hostMatch struct {
+    host      *hostRoute
+    parameter string
+}
+
+// matchHost returns the registered hosts matching request, most exact first.
+func (router *Router) matchHost(value string) []hostMatch {
+    router.lock.RLock()
+    defer router.lock.RUnlock()
+    if len(router.hosts) == 0 {
+        return nil
+    }
+    value = requestHost(value)
+    var result []hostMatch
+    for _, host := range router.hosts {
+        if parameter, ok := host.match(value); ok {
+            result = append(result, hostMatch{host: host, parameter: parameter})
+        }
+    }
+    return result
+}
@@ -0,0 +1,99 @@
+package router
+
+import (
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestHostRoute_match(t *testing.T) {
+    sample := []struct {
+        pattern, host, result string
+        ok                    bool
+    }{
+        {"api.example.com", "api.example.com", "", true},
+        {"api.example.com", "admin.example.com", "", false},
+        {"{tenant}.example.com", "acme.example.com", "tenant:acme", true},
+        {"{tenant}.{zone}.example.com", "acme.eu.example.com", "tenant:acme,zone:eu", true},
+        {"{tenant}.example.com", "example.com", "", false},
+        {"API.Example.com.", "api.example.com", "", true},
+    }
+    for _, v := range sample {
+        result, ok := newHostRoute(v.pattern).match(requestHost(v.host))
+        if ok != v.ok || result != v.result {
+            t.Errorf("%s on %s: expected %s %v got %s %v", v.pattern, v.host, v.result, v.ok, result, ok)
+        }
+    }
+}
+
+func TestMux_Host(t *testing.T) {
+    h := func(name string) RouteHandler {
+        return func(w http.ResponseWriter, r *http.Request) {
+            fmt.Fprint(w, name, GetParameter(r))
+        }
+    }
+    m := New()
+    _ = m.Get("/users/:id", h("default"))
+    _ = m.Host("admin.example.com").Get("/users/:id", h("admin"))
+    _ = m.Host("{tenant}.example.com").Group("/v1").Get("/users/:id", h("tenant"))
+
+    sample := []struct {
+        host, path, body string
+        code             int
+    }{
+        {"admin.example.com", "/users/1", "adminmap[id:1]", http.StatusOK},
+        {"acme.example.com:8080", "/v1/users/2", "tenantmap[id:2 tenant:acme]", http.StatusOK},
+        {"acme.example.com", "/users/3", "defaultmap[id:3]", http.StatusOK},
+        {"other.org", "/v1/users/4", "", http.StatusNotFound},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest(http.MethodGet, v.path, nil)
+        req.Host = v.host
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code {
+            t.Errorf("%s%s: expected %d got %d", v.host, v.path, v.code, w.Code)
+            continue
+        }
+        if v.code == http.StatusOK && w.Body.String() != v.body {
+            t.Errorf("%s%s: expected %s got %s", v.host, v.path, v.body, w.Body)
+        }
+    }
+}
+
+func TestMux_HostOrder(t *testing.T) {
+    h := func(name string) RouteHandler {
+        return func(w http.ResponseWriter, r *http.Request) {
+            fmt.Fprint(w, name, GetParameter(r))
+        }
+    }
+    register := []func(m *Mux){
+        func(m *Mux) { _ = m.Host("{tenant}.example.com").Get("/a", h("tenant")) },
+        func(m *Mux) { _ = m.Host("api.example.com").Get("/b", h("api")) },
+    }
+    for _, order := range [][]int{{0, 1}, {1, 0}} {
+        m := New()
+        for _, index := range order {
+            register[index](m)
+        }
+        sample := []struct {
+            host, path, body string
+            code             int
+        }{
+            {"api.example.com", "/b", "apimap[]", http.StatusOK},
+            {"api.example.com", "/a", "tenantmap[tenant:api]", http.StatusOK},
+            {"acme.example.com", "/a", "tenantmap[tenant:acme]", http.StatusOK},
+            {"acme.example.com", "/b", "", http.StatusNotFound},
+        }
+        for _, v := range sample {
+            req := httptest.NewRequest(http.MethodGet, v.path, nil)
+            req.Host = v.host
+            w := httptest.NewRecorder()
+            m.ServeHTTP(w, req)
+            if w.Code != v.code || v.code == http.StatusOK && w.Body.String() != v.body {
+                t.Errorf("order %v %s%s: expected %d %s got %d %s", order, v.host, v.path, v.code, v.body, w.Code, w.Body)
+            }
+        }
+    }
+}
@@ -0,0 +1,48 @@
+package router
+
+import "net/http"
+
+// limitBody caps the request bodies next reads to the limit of route. It
+// wraps the route handler inside the middlewares, so they see the overflow
+// response. Requests announcing a larger body are answered by the overflow
+// handler right away, bodies without a length fail with *http.MaxBytesError
+// once they exceed it.
+func (route *Route) limitBody(next http.Handler) http.Handler {
+    overflow := route.overflow
+    if overflow == nil {
+        overflow = http.HandlerFunc(overflowBody)
+    }
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        if request.ContentLength > route.limit {
+            overflow.ServeHTTP(response, request)
+            return
+        }
+        if request.Body != nil && request.Body != http.NoBody {
+            request.Body = http.MaxBytesReader(response, request.Body, route.limit)
+        }
+        next.ServeHTTP(response, request)
+    })
+}
+
+func overflowBody(response http.ResponseWriter, _ *http.Request) {
+    response.Header().Set("Content-Type", "text/html; charset=UTF-8")
+    response.Header().Set("Connection", "close")
+    response.WriteHeader(http.StatusRequestEntityTooLarge)
+    response.Write([]byte("413 - Request Entity Too Large"))
+}
+
+// BodyLimit returns a Mux whose routes accept request bodies of at most limit
+// bytes. Larger requests are answered with 413, or by the optional handler.
+// Groups created from the returned Mux inherit the limit
+//   api := m.Group("/api").BodyLimit(1 << 20)
+//   upload := api.BodyLimit(100 << 20)
+// Handlers reading past the limit of a body without Content-Length get a
+// *http.MaxBytesError, which the error handlers answer with 413 as well.
+func (mux *Mux) BodyLimit(limit int64, handler ...http.Handler) *Mux {
+    derived := mux.derive()
+    derived.limit = limit
+    if len(handler) > 0 {
+        derived.overflow = handler[0]
+    }
+    return derived
+}
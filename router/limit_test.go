@@ -0,0 +1,69 @@
+package router
+
+import (
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestMux_BodyLimit(t *testing.T) {
+    read := func(w http.ResponseWriter, r *http.Request) error {
+        data, err := io.ReadAll(r.Body)
+        if err != nil {
+            return err
+        }
+        _, err = w.Write(data)
+        return err
+    }
+    m := New()
+    api := m.Group("/api").BodyLimit(8)
+    _ = api.HandleError("POST", "/small", read)
+    _ = api.BodyLimit(16).HandleError("POST", "/large", read)
+
+    sample := []struct {
+        path, body string
+        chunked    bool
+        code       int
+    }{
+        {"/api/small", "12345678", false, http.StatusOK},
+        {"/api/small", "123456789", false, http.StatusRequestEntityTooLarge},
+        {"/api/small", "123456789", true, http.StatusRequestEntityTooLarge},
+        {"/api/large", "123456789", false, http.StatusOK},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("POST", v.path, strings.NewReader(v.body))
+        if v.chunked {
+            req.ContentLength = -1
+        }
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code {
+            t.Errorf("%s %s chunked %v: expected %d got %d", v.path, v.body, v.chunked, v.code, w.Code)
+        }
+    }
+}
+
+func TestMux_BodyLimitMiddleware(t *testing.T) {
+    m := New()
+    m.Use(func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            w.Header().Set("Access-Control-Allow-Origin", "*")
+            next.ServeHTTP(w, r)
+        })
+    })
+    _ = m.BodyLimit(4).Post("/upload", func(w http.ResponseWriter, r *http.Request) {
+        _, _ = io.Copy(w, r.Body)
+    })
+
+    req := httptest.NewRequest("POST", "/upload", strings.NewReader("123456789"))
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, req)
+    if w.Code != http.StatusRequestEntityTooLarge {
+        t.Fatalf("expected %d got %d", http.StatusRequestEntityTooLarge, w.Code)
+    }
+    if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+        t.Errorf("middleware skipped on overflow")
+    }
+}
@@ -0,0 +1,40 @@
+package router
+
+import "time"
+import "log/slog"
+import "net/http"
+
+// AccessLog returns a middleware logging one record per request with its
+// method, route pattern, status, size, latency, remote address and request id.
+// Unmatched requests are logged with an empty route when the middleware is
+// assigned with UseGlobal. A nil logger uses slog.Default.
+func AccessLog(logger *slog.Logger) Middleware {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+            start := time.Now()
+            writer := newResponseWriter(response)
+            next.ServeHTTP(writer, request)
+            var pattern string
+            if route := CurrentRoute(request); route != nil {
+                pattern = route.Pattern()
+            }
+            level := slog.LevelInfo
+            if writer.Status() >= http.StatusInternalServerError {
+                level = slog.LevelError
+            }
+            output := logger
+            if output == nil {
+                output = slog.Default()
+            }
+            output.LogAttrs(request.Context(), level, "request",
+                slog.String("method", request.Method),
+                slog.String("route", pattern),
+                slog.Int("status", writer.Status()),
+                slog.Int64("bytes", writer.Size()),
+                slog.Duration("latency", time.Since(start)),
+                slog.String("remote", request.RemoteAddr),
+                slog.String("request_id", RequestID(request)),
+            )
+        })
+    }
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+    "bytes"
+    "encoding/json"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestAccessLog(t *testing.T) {
+    buf := &bytes.Buffer{}
+    m := New()
+    m.UseGlobal(AccessLog(slog.New(slog.NewJSONHandler(buf, nil))), (&RequestIdentifier{}).Middleware)
+    _ = m.Post("/user/:id", func(w http.ResponseWriter, _ *http.Request) {
+        w.WriteHeader(http.StatusCreated)
+        _, _ = w.Write([]byte("created"))
+    })
+    sample := []struct {
+        method, path, route string
+        status, bytes       float64
+    }{
+        {"POST", "/user/1", "/user/:id", http.StatusCreated, 7},
+        {"GET", "/missing", "", http.StatusNotFound, 15},
+    }
+    for _, v := range sample {
+        buf.Reset()
+        req := httptest.NewRequest(v.method, v.path, nil)
+        req.Header.Set("X-Request-ID", "abc")
+        m.ServeHTTP(httptest.NewRecorder(), req)
+        record := map[string]any{}
+        if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+            t.Fatal(err, buf)
+        }
+        if record["method"] != v.method || record["route"] != v.route || record["status"] != v.status ||
+            record["bytes"] != v.bytes || record["request_id"] != "abc" {
+            t.Errorf("%s %s: unexpected record %v", v.method, v.path, record)
+        }
+    }
+}
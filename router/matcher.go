@@ -0,0 +1,163 @@
+package router
+
+import "mime"
+import "regexp"
+import "strings"
+import "net/http"
+
+// Matcher is a request predicate attached to a Route. A Route only serves a
+// request when all of its matchers hold.
+type Matcher struct {
+    status int
+    match  func(*http.Request) bool
+}
+
+// Match reports whether request satisfies the matcher.
+func (matcher Matcher) Match(request *http.Request) bool {
+    return matcher.match(request)
+}
+
+// MatchFunc returns a Matcher backed by an arbitrary predicate.
+func MatchFunc(match func(*http.Request) bool) Matcher {
+    return Matcher{status: http.StatusNotFound, match: match}
+}
+
+// MatchHeader matches when one of the comma separated values of header key
+// equals value, ignoring case and media type parameters. For instance
+//   MatchHeader("Accept", "application/vnd.x.v2+json")
+// matches
+//   Accept: text/html, application/vnd.x.v2+json;q=0.9
+func MatchHeader(key, value string) Matcher {
+    return MatchFunc(func(request *http.Request) bool {
+        for _, line := range request.Header.Values(key) {
+            for _, item := range strings.Split(line, ",") {
+                item, _, _ = strings.Cut(item, ";")
+                if strings.EqualFold(strings.TrimSpace(item), value) {
+                    return true
+                }
+            }
+        }
+        return false
+    })
+}
+
+// MatchHeaderRegexp matches when one of the values of header key matches expression.
+func MatchHeaderRegexp(key string, expression *regexp.Regexp) Matcher {
+    return MatchFunc(func(request *http.Request) bool {
+        for _, value := range request.Header.Values(key) {
+            if expression.MatchString(value) {
+                return true
+            }
+        }
+        return false
+    })
+}
+
+// MatchQuery matches when the query string contains key.
+func MatchQuery(key string) Matcher {
+    return MatchFunc(func(request *http.Request) bool {
+        return request.URL.Query().Has(key)
+    })
+}
+
+// MatchScheme matches the scheme the request was received on, "https" for
+// requests served over TLS and "http" otherwise.
+func MatchScheme(scheme string) Matcher {
+    return MatchFunc(func(request *http.Request) bool {
+        value := "http"
+        if request.TLS != nil {
+            value = "https"
+        }
+        return strings.EqualFold(scheme, value)
+    })
+}
+
+// MatchContentType matches when the Content-Type of the request is one of
+// media. A media of the form "type/*" accepts every subtype. When no route of
+// a pattern matches because of this matcher the Mux answers with 415.
+func MatchContentType(media ...string) Matcher {
+    return Matcher{
+        status: http.StatusUnsupportedMediaType,
+        match: func(request *http.Request) bool {
+            value, _, exception := mime.ParseMediaType(request.Header.Get("Content-Type"))
+            if exception != nil {
+                return false
+            }
+            for _, expect := range media {
+                expect = strings.ToLower(expect)
+                if expect == value {
+                    return true
+                }
+                if prefix, ok := strings.CutSuffix(expect, "/*"); ok && strings.HasPrefix(value, prefix+"/") {
+                    return true
+                }
+            }
+            return false
+        },
+    }
+}
+
+// chain links value into the candidates sharing the pattern of route. Routes
+// with matchers are tried before routes without, each in registration order.
+func (route *Route) chain(value *Route) *Route {
+    head := &Route{next: route}
+    level := head
+    for level.next != nil && (len(value.matcher) == 0 || len(level.next.matcher) > 0) {
+        level = level.next
+    }
+    value.next = level.next
+    level.next = value
+    return head.next
+}
+
+// pick returns the first candidate whose matchers all hold for request, or
+// the status to answer with when none does.
+func (route *Route) pick(request *http.Request) (*Route, int) {
+    var status = http.StatusNotFound
+    for level := route; level != nil; level = level.next {
+        var ok = true
+        for _, matcher := range level.matcher {
+            if !matcher.Match(request) {
+                if matcher.status == http.StatusUnsupportedMediaType {
+                    status = matcher.status
+                }
+                ok = false
+                break
+            }
+        }
+        if ok {
+            return level, http.StatusOK
+        }
+    }
+    return nil, status
+}
+
+// lookup finds the Route serving request at url.
+func (router *Router) lookup(request *http.Request, url string) (*Route, int) {
+    route, exception := router.find(request.Method, url)
+    if exception != nil {
+        return nil, http.StatusNotFound
+    }
+    return route.pick(request)
+}
+
+// Match returns a Mux whose routes only serve requests satisfying all of
+// matcher. Several routes may share a pattern, for instance
+//   m := New()
+//   m.Match(MatchHeader("Accept", "application/vnd.x.v2+json")).Get("/users", v2)
+//   m.Get("/users", v1)
+// serves v2 to clients asking for it and v1 to everyone else.
+func (mux *Mux) Match(matcher ...Matcher) *Mux {
+    derived := mux.derive()
+    derived.matcher = append(derived.matcher, matcher...)
+    return derived
+}
+
+// UnsupportedMediaTypeHandler sets the handler used when a route pattern
+// matches but none of its routes accept the Content-Type of the request. Set
+// on a Group or Host it only answers the requests under that prefix or host,
+// like NotFoundHandler.
+func (mux *Mux) UnsupportedMediaTypeHandler(handler http.Handler) {
+    mux.unsupported = handler
+    mux.scope(&mux.Router.unsupported, handler)
+}
@@ -0,0 +1,120 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "regexp"
+    "testing"
+)
+
+func TestMux_Match(t *testing.T) {
+    h := func(name string) RouteHandler {
+        return func(w http.ResponseWriter, _ *http.Request) {
+            _, _ = w.Write([]byte(name))
+        }
+    }
+    m := New()
+    _ = m.Get("/users", h("v1"))
+    _ = m.Match(MatchHeader("Accept", "application/vnd.x.v2+json")).Get("/users", h("v2"))
+    _ = m.Match(MatchHeaderRegexp("Accept", regexp.MustCompile(`v3`)), MatchQuery("debug")).Get("/users", h("v3"))
+    _ = m.Match(MatchContentType("application/json")).Post("/users", h("json"))
+    _ = m.Match(MatchContentType("text/*")).Post("/users", h("text"))
+    _ = m.Match(MatchScheme("https")).Get("/secure", h("secure"))
+
+    sample := []struct {
+        method, path, header, value, body string
+        code                              int
+    }{
+        {"GET", "/users", "", "", "v1", http.StatusOK},
+        {"GET", "/users", "Accept", "text/html, application/vnd.x.v2+json;q=0.9", "v2", http.StatusOK},
+        {"GET", "/users?debug", "Accept", "application/vnd.x.v3+json", "v3", http.StatusOK},
+        {"GET", "/users", "Accept", "application/vnd.x.v3+json", "v1", http.StatusOK},
+        {"POST", "/users", "Content-Type", "application/json; charset=utf-8", "json", http.StatusOK},
+        {"POST", "/users", "Content-Type", "text/csv", "text", http.StatusOK},
+        {"POST", "/users", "Content-Type", "application/xml", "", http.StatusUnsupportedMediaType},
+        {"GET", "/secure", "", "", "", http.StatusNotFound},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest(v.method, v.path, nil)
+        if v.header != "" {
+            req.Header.Set(v.header, v.value)
+        }
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code {
+            t.Errorf("%s %s %s: expected %d got %d", v.method, v.path, v.value, v.code, w.Code)
+            continue
+        }
+        if v.code == http.StatusOK && w.Body.String() != v.body {
+            t.Errorf("%s %s %s: expected %s got %s", v.method, v.path, v.value, v.body, w.Body)
+        }
+    }
+}
+
+func TestRoute_chain(t *testing.T) {
+    always := MatchFunc(func(_ *http.Request) bool { return true })
+    var head *Route
+    for _, v := range []*Route{
+        {path: "a"},
+        {path: "b", matcher: []Matcher{always}},
+        {path: "c"},
+        {path: "d", matcher: []Matcher{always}},
+    } {
+        if head == nil {
+            head = v
+            continue
+        }
+        head = head.chain(v)
+    }
+    var order string
+    for level := head; level != nil; level = level.next {
+        order += level.path
+    }
+    if order != "bdac" {
+        t.Errorf("expected bdac got %s", order)
+    }
+}
+
+func TestMux_UnsupportedMediaTypeHandlerScope(t *testing.T) {
+    scoped := func(body string) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            w.WriteHeader(http.StatusUnsupportedMediaType)
+            _, _ = w.Write([]byte(body))
+        })
+    }
+    h := func(w http.ResponseWriter, r *http.Request) {}
+    json := MatchContentType("application/json")
+    m := New()
+    _ = m.Match(json).Post("/users", h)
+    api := m.Group("/api").Match(json)
+    api.UnsupportedMediaTypeHandler(scoped("api"))
+    _ = api.Post("/users", h)
+    docs := m.Host("docs.example.com").Match(json)
+    docs.UnsupportedMediaTypeHandler(scoped("docs"))
+    _ = docs.Post("/users", h)
+    sample := []struct {
+        host, path, body string
+    }{
+        {"example.com", "/api/users", "api"},
+        {"docs.example.com", "/users", "docs"},
+        {"example.com", "/users", "415 - Unsupported Media Type"},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("POST", v.path, nil)
+        req.Host = v.host
+        req.Header.Set("Content-Type", "text/plain")
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != http.StatusUnsupportedMediaType || w.Body.String() != v.body {
+            t.Errorf("%s%s: expected %q got %d %q", v.host, v.path, v.body, w.Code, w.Body)
+        }
+    }
+    m.Match(json).UnsupportedMediaTypeHandler(scoped("root"))
+    w := httptest.NewRecorder()
+    req := httptest.NewRequest("POST", "/users", nil)
+    req.Header.Set("Content-Type", "text/plain")
+    m.ServeHTTP(w, req)
+    if w.Body.String() != "root" {
+        t.Errorf("expected root got %q", w.Body)
+    }
+}
@@ -0,0 +1,148 @@
+package router
+
+import "sort"
+import "sync"
+import "time"
+import "strconv"
+import "strings"
+import "net/http"
+import "slices"
+
+// DefaultBucket are the latency buckets of Metrics, in seconds.
+var DefaultBucket = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// unmatchedRoute labels the requests no route matched.
+const unmatchedRoute = "unmatched"
+
+// Metrics counts requests and their latency by method, route pattern and
+// status class. Assign Middleware with UseGlobal and mount Metrics itself to
+// expose the Prometheus text format
+//   metrics := &Metrics{}
+//   m.UseGlobal(metrics.Middleware)
+//   m.HandleGet("/metrics", metrics)
+type Metrics struct {
+    // Bucket are the upper bounds of the latency histogram, it defaults to DefaultBucket.
+    Bucket []float64
+
+    once   sync.Once
+    lock   Lock
+    series map[metricKey]*metricSeries
+}
+
+type metricKey struct {
+    method string
+    route  string
+    class  string
+}
+
+type metricSeries struct {
+    lock   sync.Mutex
+    count  uint64
+    sum    float64
+    bucket []uint64
+}
+
+func (metrics *Metrics) init() {
+    metrics.once.Do(func() {
+        if len(metrics.Bucket) == 0 {
+            metrics.Bucket = DefaultBucket
+        }
+        metrics.Bucket = slices.Clone(metrics.Bucket)
+        slices.Sort(metrics.Bucket)
+        metrics.series = make(map[metricKey]*metricSeries)
+    })
+}
+
+// Middleware records every request served by next.
+func (metrics *Metrics) Middleware(next http.Handler) http.Handler {
+    metrics.init()
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        start := time.Now()
+        writer := newResponseWriter(response)
+        next.ServeHTTP(writer, request)
+        key := metricKey{
+            method: "OTHER",
+            route:  unmatchedRoute,
+            class:  strconv.Itoa(writer.Status()/100) + "xx",
+        }
+        if slices.Contains(AllMethod, request.Method) {
+            key.method = request.Method
+        }
+        if route := CurrentRoute(request); route != nil {
+            key.route = route.Pattern()
+        }
+        metrics.observe(key, time.Since(start).Seconds())
+    })
+}
+
+func (metrics *Metrics) observe(key metricKey, elapsed float64) {
+    metrics.lock.RLock()
+    series, ok := metrics.series[key]
+    metrics.lock.RUnlock()
+    if !ok {
+        metrics.lock.Lock()
+        if series, ok = metrics.series[key]; !ok {
+            series = &metricSeries{bucket: make([]uint64, len(metrics.Bucket))}
+            metrics.series[key] = series
+        }
+        metrics.lock.Unlock()
+    }
+    series.lock.Lock()
+    defer series.lock.Unlock()
+    series.count++
+    series.sum += elapsed
+    for index, bound := range metrics.Bucket {
+        if elapsed <= bound {
+            series.bucket[index]++
+        }
+    }
+}
+
+// ServeHTTP writes the recorded metrics in the Prometheus text exposition format.
+func (metrics *Metrics) ServeHTTP(response http.ResponseWriter, _ *http.Request) {
+    metrics.init()
+    metrics.lock.RLock()
+    key := make([]metricKey, 0, len(metrics.series))
+    for value := range metrics.series {
+        key = append(key, value)
+    }
+    metrics.lock.RUnlock()
+    sort.Slice(key, func(i, j int) bool {
+        if key[i].route != key[j].route {
+            return key[i].route < key[j].route
+        }
+        if key[i].method != key[j].method {
+            return key[i].method < key[j].method
+        }
+        return key[i].class < key[j].class
+    })
+    var counter, histogram strings.Builder
+    counter.WriteString("# HELP http_requests_total Requests served by the router.\n")
+    counter.WriteString("# TYPE http_requests_total counter\n")
+    histogram.WriteString("# HELP http_request_duration_seconds Latency of the requests served by the router.\n")
+    histogram.WriteString("# TYPE http_request_duration_seconds histogram\n")
+    for _, value := range key {
+        metrics.lock.RLock()
+        series := metrics.series[value]
+        metrics.lock.RUnlock()
+        series.lock.Lock()
+        count, sum, bucket := series.count, series.sum, slices.Clone(series.bucket)
+        series.lock.Unlock()
+        label := `method="` + escapeLabel(value.method) + `",route="` + escapeLabel(value.route) + `",status="` + value.class + `"`
+        counter.WriteString("http_requests_total{" + label + "} " + strconv.FormatUint(count, 10) + "\n")
+        for index, bound := range metrics.Bucket {
+            histogram.WriteString("http_request_duration_seconds_bucket{" + label + `,le="` + strconv.FormatFloat(bound, 'g', -1, 64) + `"} ` + strconv.FormatUint(bucket[index], 10) + "\n")
+        }
+        histogram.WriteString("http_request_duration_seconds_bucket{" + label + `,le="+Inf"} ` + strconv.FormatUint(count, 10) + "\n")
+        histogram.WriteString("http_request_duration_seconds_sum{" + label + "} " + strconv.FormatFloat(sum, 'g', -1, 64) + "\n")
+        histogram.WriteString("http_request_duration_seconds_count{" + label + "} " + strconv.FormatUint(count, 10) + "\n")
+    }
+    response.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+    response.Write([]byte(counter.String() + histogram.String()))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+    return labelEscaper.Replace(value)
+}
@@ -0,0 +1,45 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestMetrics(t *testing.T) {
+    metrics := &Metrics{Bucket: []float64{60, 1}}
+    m := New()
+    m.UseGlobal(metrics.Middleware)
+    _ = m.Get("/user/:id", func(_ http.ResponseWriter, _ *http.Request) {})
+    _ = m.HandleGet("/metrics", metrics)
+    for _, path := range []string{"/user/1", "/user/2", "/missing/1", "/missing/2"} {
+        m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+    }
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+    body := w.Body.String()
+    for _, line := range []string{
+        "# TYPE http_requests_total counter\n",
+        `http_requests_total{method="GET",route="/user/:id",status="2xx"} 2` + "\n",
+        `http_requests_total{method="GET",route="unmatched",status="4xx"} 2` + "\n",
+        "# TYPE http_request_duration_seconds histogram\n",
+        `http_request_duration_seconds_bucket{method="GET",route="/user/:id",status="2xx",le="1"} 2` + "\n",
+        `http_request_duration_seconds_bucket{method="GET",route="/user/:id",status="2xx",le="60"} 2` + "\n",
+        `http_request_duration_seconds_bucket{method="GET",route="/user/:id",status="2xx",le="+Inf"} 2` + "\n",
+        `http_request_duration_seconds_count{method="GET",route="unmatched",status="4xx"} 2` + "\n",
+    } {
+        if !strings.Contains(body, line) {
+            t.Errorf("expected %q in\n%s", line, body)
+        }
+    }
+    if strings.Contains(body, "/missing") {
+        t.Errorf("expected raw paths to stay out of the labels\n%s", body)
+    }
+}
+
+func TestEscapeLabel(t *testing.T) {
+    if value := escapeLabel("a\"b\\c\nd"); value != `a\"b\\c\nd` {
+        t.Errorf("unexpected escape %s", value)
+    }
+}
@@ -0,0 +1,82 @@
+package router
+
+import "path"
+import "context"
+import "strings"
+import "net/http"
+
+// Mount attaches handler under prefix for every http method. The handler sees
+// URL.Path relative to prefix, the path the request was received with stays
+// available through OriginalPath. For instance
+//   m := New()
+//   m.Mount("/static", http.FileServer(http.Dir("public")))
+// serves public/css/site.css for /static/css/site.css.
+func (mux *Mux) Mount(prefix string, handler http.Handler) error {
+    point := path.Join(mux.prefix, prefix)
+    serve := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        handler.ServeHTTP(response, stripPrefix(request, point))
+    })
+    for _, method := range AllMethod {
+        if exception := mux.Handle(method, prefix, serve); exception != nil {
+            return exception
+        }
+        if exception := mux.Handle(method, path.Join(prefix, "*"), serve); exception != nil {
+            return exception
+        }
+    }
+    return nil
+}
+
+// stripPrefix returns a shallow copy of request with the segments of prefix
+// removed from its cleaned path, the one routing matched, and without the
+// catch all parameter of the mount point.
+func stripPrefix(request *http.Request, prefix string) *http.Request {
+    ctx := request.Context()
+    if _, ok := ctx.Value(keyOriginalPath).(string); !ok {
+        ctx = context.WithValue(ctx, keyOriginalPath, request.URL.Path)
+    }
+    if state := stateOf(request); state != nil {
+        if _, ok := state.parameter["catch"]; ok {
+            value := *state
+            value.parameter = make(Parameter, len(state.parameter))
+            for key, data := range state.parameter {
+                if key != "catch" {
+                    value.parameter[key] = data
+                }
+            }
+            ctx = context.WithValue(ctx, keyState, &value)
+        }
+    }
+    clone := request.WithContext(ctx)
+    clone.Header = request.Header.Clone()
+    clone.Header.Del(headerName)
+    url := *request.URL
+    cleaned := path.Clean("/" + url.Path)
+    if strings.HasSuffix(url.Path, "/") && cleaned != "/" {
+        cleaned += "/"
+    }
+    // drop as many segments as prefix holds, its params match any value
+    if prefix = strings.Trim(prefix, "/"); prefix != "" {
+        for count := strings.Count(prefix, "/") + 1; count > 0; count-- {
+            index := strings.IndexByte(cleaned[1:], '/')
+            if index < 0 {
+                cleaned = "/"
+                break
+            }
+            cleaned = cleaned[index+1:]
+        }
+    }
+    url.Path = cleaned
+    url.RawPath = ""
+    clone.URL = &url
+    return clone
+}
+
+// OriginalPath returns the path request was received with before any Mount
+// rewrote it.
+func OriginalPath(request *http.Request) string {
+    if value, ok := request.Context().Value(keyOriginalPath).(string); ok {
+        return value
+    }
+    return request.URL.Path
+}
@@ -0,0 +1,48 @@
+package router
+
+import (
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestMux_Mount(t *testing.T) {
+    h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        fmt.Fprintf(w, "%s %s %v %q", r.URL.Path, OriginalPath(r), GetParameter(r), r.Header.Get(headerName))
+    })
+    inner := New()
+    _ = inner.Get("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+        fmt.Fprintf(w, "%s %s %s", GetParameter(r).Get("id"), r.URL.Path, OriginalPath(r))
+    })
+    m := New()
+    _ = m.Mount("/static", h)
+    _ = m.Group("/api").Mount("/v1", inner)
+    _ = m.Mount("/tenant/:name", h)
+
+    sample := []struct {
+        method, path, body string
+        code               int
+    }{
+        {"GET", "/static/css/site.css", "/css/site.css /static/css/site.css map[] \"\"", http.StatusOK},
+        {"GET", "//static//css/../css/site.css", "/css/site.css //static//css/../css/site.css map[] \"\"", http.StatusOK},
+        {"GET", "/static/css/", "/css/ /static/css/ map[] \"\"", http.StatusOK},
+        {"DELETE", "/static", "/ /static map[] \"\"", http.StatusOK},
+        {"GET", "/tenant/acme/a/b", "/a/b /tenant/acme/a/b map[name:acme] \"\"", http.StatusOK},
+        {"GET", "/api/v1/users/7", "7 /users/7 /api/v1/users/7", http.StatusOK},
+        {"GET", "/api/v1/other", "", http.StatusNotFound},
+        {"GET", "/statics", "", http.StatusNotFound},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest(v.method, v.path, nil)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code {
+            t.Errorf("%s %s: expected %d got %d", v.method, v.path, v.code, w.Code)
+            continue
+        }
+        if v.code == http.StatusOK && w.Body.String() != v.body {
+            t.Errorf("%s %s: expected %s got %s", v.method, v.path, v.body, w.Body)
+        }
+    }
+}
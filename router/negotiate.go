@@ -0,0 +1,165 @@
+package router
+
+import "io"
+import "bytes"
+import "strconv"
+import "strings"
+import "net/http"
+import "encoding/xml"
+import "encoding/json"
+
+// Renderer encodes value in the media type it is registered for.
+type Renderer = func(io.Writer, any) error
+
+// RenderJSON is a Renderer for application/json.
+func RenderJSON(writer io.Writer, value any) error {
+    return json.NewEncoder(writer).Encode(value)
+}
+
+// RenderXML is a Renderer for application/xml.
+func RenderXML(writer io.Writer, value any) error {
+    if _, exception := io.WriteString(writer, xml.Header); exception != nil {
+        return exception
+    }
+    return xml.NewEncoder(writer).Encode(value)
+}
+
+type renderer struct {
+    media  string
+    render Renderer
+}
+
+// accept is a media range of an Accept header.
+type accept struct {
+    media   string
+    quality float64
+}
+
+func parseAccept(header string) []accept {
+    var result []accept
+    for _, item := range strings.Split(header, ",") {
+        media, parameter, _ := strings.Cut(item, ";")
+        value := accept{media: strings.ToLower(strings.TrimSpace(media)), quality: 1}
+        if value.media == "" {
+            continue
+        }
+        for _, part := range strings.Split(parameter, ";") {
+            key, data, _ := strings.Cut(strings.TrimSpace(part), "=")
+            if strings.EqualFold(key, "q") {
+                if quality, exception := strconv.ParseFloat(data, 64); exception == nil {
+                    value.quality = quality
+                }
+            }
+        }
+        result = append(result, value)
+    }
+    return result
+}
+
+// specificity ranks how closely media matches offer, 0 when it does not.
+func specificity(media, offer string) int {
+    switch {
+    case media == offer:
+        return 3
+    case media == "*/*":
+        return 1
+    case strings.HasSuffix(media, "/*") && strings.HasPrefix(offer, media[:len(media)-1]):
+        return 2
+    }
+    return 0
+}
+
+// Negotiate returns the offer the Accept header prefers, honoring q-values
+// and wildcards. The most specific media range matching an offer decides its
+// quality, ties go to the earlier offer. An empty header accepts the first
+// offer, an empty result means nothing is acceptable.
+func Negotiate(header string, offer ...string) string {
+    if strings.TrimSpace(header) == "" {
+        if len(offer) > 0 {
+            return offer[0]
+        }
+        return ""
+    }
+    list := parseAccept(header)
+    var result string
+    var best float64
+    for _, value := range offer {
+        lower := strings.ToLower(value)
+        var rank int
+        var quality float64
+        for _, media := range list {
+            if current := specificity(media.media, lower); current > rank {
+                rank, quality = current, media.quality
+            }
+        }
+        if quality > best {
+            result, best = value, quality
+        }
+    }
+    return result
+}
+
+// Renderer registers render for media. Render offers media types in their
+// registration order.
+func (mux *Mux) Renderer(media string, render Renderer) {
+    mux.Router.lock.Lock()
+    defer mux.Router.lock.Unlock()
+    for index, value := range mux.Router.renderer {
+        if value.media == media {
+            mux.Router.renderer[index].render = render
+            return
+        }
+    }
+    mux.Router.renderer = append(mux.Router.renderer, renderer{media: media, render: render})
+}
+
+// NotAcceptableHandler sets the handler used by Render when no registered
+// media type is acceptable.
+func (mux *Mux) NotAcceptableHandler(handler http.Handler) {
+    mux.notAcceptable = handler
+}
+
+// Render writes value with status in the registered media type the client
+// prefers, for instance
+//   m.Renderer("application/json", RenderJSON)
+//   m.Renderer("application/xml", RenderXML)
+//   m.Get("/user", func(w http.ResponseWriter, r *http.Request) {
+//       m.Render(w, r, http.StatusOK, user)
+//   })
+// When no media type is acceptable the response is written by the
+// NotAcceptableHandler, a plain 406 by default.
+func (mux *Mux) Render(response http.ResponseWriter, request *http.Request, status int, value any) error {
+    mux.Router.lock.RLock()
+    list := append([]renderer(nil), mux.Router.renderer...)
+    mux.Router.lock.RUnlock()
+    offer := make([]string, 0, len(list))
+    for _, item := range list {
+        offer = append(offer, item.media)
+    }
+    response.Header().Add("Vary", "Accept")
+    media := Negotiate(request.Header.Get("Accept"), offer...)
+    if media == "" {
+        handler := mux.notAcceptable
+        if handler == nil {
+            handler = http.HandlerFunc(notAcceptable)
+        }
+        handler.ServeHTTP(response, request)
+        return nil
+    }
+    var buffer bytes.Buffer
+    for _, item := range list {
+        if item.media == media {
+            if exception := item.render(&buffer, value); exception != nil {
+                return WrapHTTPError(http.StatusInternalServerError, exception)
+            }
+            break
+        }
+    }
+    return write(response, status, media, buffer.Bytes())
+}
+
+func notAcceptable(response http.ResponseWriter, _ *http.Request) {
+    response.Header().Set("Content-Type", "text/html; charset=UTF-8")
+    response.WriteHeader(http.StatusNotAcceptable)
+    response.Write([]byte("406 - Not Acceptable"))
+}
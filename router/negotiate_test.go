@@ -0,0 +1,66 @@
+package router
+
+import (
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestNegotiate(t *testing.T) {
+    offer := []string{"application/json", "application/xml", "text/csv"}
+    sample := []struct {
+        accept, result string
+    }{
+        {"", "application/json"},
+        {"*/*", "application/json"},
+        {"application/xml", "application/xml"},
+        {"text/*;q=0.9, application/json;q=0.5", "text/csv"},
+        {"application/*;q=0.2, application/xml;q=0.8", "application/xml"},
+        {"*/*;q=0.1, application/json;q=0", "application/xml"},
+        {"image/png", ""},
+        {"APPLICATION/XML", "application/xml"},
+    }
+    for _, v := range sample {
+        if result := Negotiate(v.accept, offer...); result != v.result {
+            t.Errorf("%q: expected %q got %q", v.accept, v.result, result)
+        }
+    }
+}
+
+func TestMux_Render(t *testing.T) {
+    type User struct {
+        Name string `json:"name" xml:"name"`
+    }
+    m := New()
+    m.Renderer("application/json", RenderJSON)
+    m.Renderer("application/xml", RenderXML)
+    m.Renderer("text/csv", func(w io.Writer, v any) error {
+        _, err := io.WriteString(w, "name\n"+v.(User).Name+"\n")
+        return err
+    })
+    _ = m.Get("/user", func(w http.ResponseWriter, r *http.Request) {
+        _ = m.Render(w, r, http.StatusOK, User{Name: "alien"})
+    })
+    sample := []struct {
+        accept, media, body string
+        code                int
+    }{
+        {"application/json", "application/json", "{\"name\":\"alien\"}\n", http.StatusOK},
+        {"application/xml", "application/xml", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<User><name>alien</name></User>", http.StatusOK},
+        {"text/csv", "text/csv", "name\nalien\n", http.StatusOK},
+        {"image/png", "text/html; charset=UTF-8", "406 - Not Acceptable", http.StatusNotAcceptable},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", "/user", nil)
+        req.Header.Set("Accept", v.accept)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code || w.Header().Get("Content-Type") != v.media || w.Body.String() != v.body {
+            t.Errorf("%s: expected %d %s %q got %d %s %q", v.accept, v.code, v.media, v.body, w.Code, w.Header().Get("Content-Type"), w.Body)
+        }
+        if w.Header().Get("Vary") != "Accept" {
+            t.Errorf("%s: expected Vary: Accept", v.accept)
+        }
+    }
+}
@@ -0,0 +1,261 @@
+package router
+
+import "time"
+import "errors"
+import "reflect"
+import "strconv"
+import "strings"
+import "net/http"
+import "encoding"
+import "encoding/hex"
+
+var (
+    ErrorParameterMissing = errors.New("parameter missing")
+
+    errBadUUID = errors.New("invalid uuid")
+)
+
+// FieldError is the failure of a single parameter or struct field.
+type FieldError struct {
+    Field  string
+    Source string
+    Value  string
+    Err    error
+}
+
+func (exception *FieldError) Error() string {
+    if exception.Source == "" {
+        return exception.Field + ": " + exception.Err.Error()
+    }
+    return exception.Source + " " + exception.Field + ": " + exception.Err.Error()
+}
+
+func (exception *FieldError) Unwrap() error {
+    return exception.Err
+}
+
+// BindError lists the fields Bind failed to fill.
+type BindError []*FieldError
+
+func (exception BindError) Error() string {
+    list := make([]string, 0, len(exception))
+    for _, value := range exception {
+        list = append(list, value.Error())
+    }
+    return strings.Join(list, "; ")
+}
+
+// UUID is a RFC 4122 universally unique identifier.
+type UUID [16]byte
+
+// ParseUUID parses the canonical 8-4-4-4-12 form of a UUID.
+func ParseUUID(value string) (UUID, error) {
+    var result UUID
+    if len(value) != 36 || value[8] != '-' || value[13] != '-' || value[18] != '-' || value[23] != '-' {
+        return result, errBadUUID
+    }
+    data := value[:8] + value[9:13] + value[14:18] + value[19:23] + value[24:]
+    if _, exception := hex.Decode(result[:], []byte(data)); exception != nil {
+        return result, errBadUUID
+    }
+    return result, nil
+}
+
+func (uuid UUID) String() string {
+    data := hex.EncodeToString(uuid[:])
+    return data[:8] + "-" + data[8:12] + "-" + data[12:16] + "-" + data[16:20] + "-" + data[20:]
+}
+
+func (uuid *UUID) UnmarshalText(data []byte) (err error) {
+    *uuid, err = ParseUUID(string(data))
+    return
+}
+
+// parameter returns the route parameter name of request.
+func parameter(request *http.Request, name string) (string, error) {
+    value, ok := GetParameter(request)[name]
+    if !ok {
+        return "", &FieldError{Field: name, Source: "path", Err: ErrorParameterMissing}
+    }
+    return value, nil
+}
+
+// ParamInt returns the route parameter name of request as an int.
+func ParamInt(request *http.Request, name string) (int, error) {
+    value, exception := parameterInt(request, name, strconv.IntSize)
+    return int(value), exception
+}
+
+// ParamInt64 returns the route parameter name of request as an int64.
+func ParamInt64(request *http.Request, name string) (int64, error) {
+    return parameterInt(request, name, 64)
+}
+
+func parameterInt(request *http.Request, name string, size int) (int64, error) {
+    value, exception := parameter(request, name)
+    if exception != nil {
+        return 0, exception
+    }
+    result, exception := strconv.ParseInt(value, 10, size)
+    if exception != nil {
+        return 0, &FieldError{Field: name, Source: "path", Value: value, Err: errors.Unwrap(exception)}
+    }
+    return result, nil
+}
+
+// ParamUUID returns the route parameter name of request as a UUID.
+func ParamUUID(request *http.Request, name string) (UUID, error) {
+    value, exception := parameter(request, name)
+    if exception != nil {
+        return UUID{}, exception
+    }
+    result, exception := ParseUUID(value)
+    if exception != nil {
+        return result, &FieldError{Field: name, Source: "path", Value: value, Err: exception}
+    }
+    return result, nil
+}
+
+// ParamTime returns the route parameter name of request parsed with layout.
+func ParamTime(request *http.Request, name, layout string) (time.Time, error) {
+    value, exception := parameter(request, name)
+    if exception != nil {
+        return time.Time{}, exception
+    }
+    result, exception := time.Parse(layout, value)
+    if exception != nil {
+        return result, &FieldError{Field: name, Source: "path", Value: value, Err: exception}
+    }
+    return result, nil
+}
+
+var (
+    typeTime     = reflect.TypeOf(time.Time{})
+    typeDuration = reflect.TypeOf(time.Duration(0))
+    typeText     = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bind fills the fields of the struct destination points to from the route
+// parameters and the query string of request. Fields are selected with tags
+//   type Filter struct {
+//       ID   int      `path:"id"`
+//       Page int      `query:"page"`
+//       Tag  []string `query:"tag"`
+//   }
+// Absent values leave their field untouched. Every field failing to convert
+// is reported in the returned BindError.
+func Bind(request *http.Request, destination any) error {
+    value := reflect.ValueOf(destination)
+    if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+        return errors.New("bind destination must be a pointer to a struct")
+    }
+    var result BindError
+    bindStruct(value.Elem(), GetParameter(request), request.URL.Query(), &result)
+    if len(result) > 0 {
+        return result
+    }
+    return nil
+}
+
+func bindStruct(value reflect.Value, path Parameter, query map[string][]string, result *BindError) {
+    for index := 0; index < value.NumField(); index++ {
+        field := value.Type().Field(index)
+        if !field.IsExported() {
+            continue
+        }
+        if field.Anonymous && field.Type.Kind() == reflect.Struct {
+            bindStruct(value.Field(index), path, query, result)
+            continue
+        }
+        var source, name string
+        var list []string
+        if name = field.Tag.Get("path"); name != "" {
+            if data, ok := path[name]; ok {
+                source, list = "path", []string{data}
+            }
+        } else if name = field.Tag.Get("query"); name != "" {
+            if data, ok := query[name]; ok {
+                source, list = "query", data
+            }
+        }
+        if len(list) == 0 {
+            continue
+        }
+        if exception := bindValue(value.Field(index), list); exception != nil {
+            *result = append(*result, &FieldError{Field: name, Source: source, Value: strings.Join(list, ","), Err: exception})
+        }
+    }
+}
+
+func bindValue(value reflect.Value, list []string) error {
+    if value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8 {
+        slice := reflect.MakeSlice(value.Type(), len(list), len(list))
+        for index, data := range list {
+            if exception := convert(slice.Index(index), data); exception != nil {
+                return exception
+            }
+        }
+        value.Set(slice)
+        return nil
+    }
+    return convert(value, list[0])
+}
+
+// convert parses data into value.
+func convert(value reflect.Value, data string) error {
+    if value.Kind() == reflect.Pointer {
+        if value.IsNil() {
+            value.Set(reflect.New(value.Type().Elem()))
+        }
+        return convert(value.Elem(), data)
+    }
+    switch {
+    case value.Type() == typeTime:
+        result, exception := time.Parse(time.RFC3339, data)
+        if exception != nil {
+            return exception
+        }
+        value.Set(reflect.ValueOf(result))
+        return nil
+    case value.Type() == typeDuration:
+        result, exception := time.ParseDuration(data)
+        if exception != nil {
+            return exception
+        }
+        value.SetInt(int64(result))
+        return nil
+    case reflect.PointerTo(value.Type()).Implements(typeText):
+        return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(data))
+    }
+    switch value.Kind() {
+    case reflect.String:
+        value.SetString(data)
+    case reflect.Bool:
+        result, exception := strconv.ParseBool(data)
+        if exception != nil {
+            return errors.Unwrap(exception)
+        }
+        value.SetBool(result)
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+        result, exception := strconv.ParseInt(data, 10, value.Type().Bits())
+        if exception != nil {
+            return errors.Unwrap(exception)
+        }
+        value.SetInt(result)
+    case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        result, exception := strconv.ParseUint(data, 10, value.Type().Bits())
+        if exception != nil {
+            return errors.Unwrap(exception)
+        }
+        value.SetUint(result)
+    case reflect.Float32, reflect.Float64:
+        result, exception := strconv.ParseFloat(data, value.Type().Bits())
+        if exception != nil {
+            return errors.Unwrap(exception)
+        }
+        value.SetFloat(result)
+    default:
+        return errors.New("unsupported type " + value.Type().String())
+    }
+    return nil
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+    "time"
+)
+
+func TestParam(t *testing.T) {
+    m := New()
+    _ = m.Get("/item/:id/:uuid/:day", func(w http.ResponseWriter, r *http.Request) {
+        id, err := ParamInt(r, "id")
+        if err != nil || id != 42 {
+            t.Errorf("expected 42 got %d %v", id, err)
+        }
+        large, err := ParamInt64(r, "id")
+        if err != nil || large != 42 {
+            t.Errorf("expected 42 got %d %v", large, err)
+        }
+        uuid, err := ParamUUID(r, "uuid")
+        if err != nil || uuid.String() != "123e4567-e89b-12d3-a456-426614174000" {
+            t.Errorf("unexpected uuid %s %v", uuid, err)
+        }
+        day, err := ParamTime(r, "day", time.DateOnly)
+        if err != nil || day.Format(time.DateOnly) != "2024-02-29" {
+            t.Errorf("unexpected day %s %v", day, err)
+        }
+        if _, err = ParamInt(r, "day"); !errors.Is(err, strconv.ErrSyntax) {
+            t.Errorf("expected a syntax error got %v", err)
+        }
+        if _, err = ParamInt(r, "missing"); !errors.Is(err, ErrorParameterMissing) {
+            t.Errorf("expected a missing error got %v", err)
+        }
+        if _, err = ParamUUID(r, "id"); err == nil {
+            t.Error("expected an error")
+        }
+    })
+    _ = m.Get("/at/:when/:tags", func(w http.ResponseWriter, r *http.Request) {
+        when, err := ParamTime(r, "when", time.RFC3339)
+        if err != nil || !when.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+            t.Errorf("unexpected time %s %v", when, err)
+        }
+        var bound struct {
+            When time.Time `path:"when"`
+        }
+        if err = Bind(r, &bound); err != nil || !bound.When.Equal(when) {
+            t.Errorf("unexpected bound time %s %v", bound.When, err)
+        }
+        if tags := GetParameter(r).Get("tags"); tags != "a,b:c" {
+            t.Errorf("expected a,b:c got %q", tags)
+        }
+    })
+    for _, path := range []string{"/item/42/123e4567-e89b-12d3-a456-426614174000/2024-02-29", "/at/2024-01-02T03:04:05Z/a,b:c"} {
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+        if w.Code != http.StatusOK {
+            t.Errorf("%s: expected %d got %d", path, http.StatusOK, w.Code)
+        }
+    }
+}
+
+func TestBind(t *testing.T) {
+    type Page struct {
+        Page int `query:"page"`
+        Size *int `query:"size"`
+    }
+    type Filter struct {
+        Page
+        ID      int64         `path:"id"`
+        Team    UUID          `path:"team"`
+        Tag     []string      `query:"tag"`
+        Active  bool          `query:"active"`
+        Since   time.Time     `query:"since"`
+        Timeout time.Duration `query:"timeout"`
+        Ignored string
+    }
+    var filter Filter
+    var bind error
+    m := New()
+    _ = m.Get("/team/:team/user/:id", func(_ http.ResponseWriter, r *http.Request) {
+        filter = Filter{Ignored: "kept"}
+        bind = Bind(r, &filter)
+    })
+
+    m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET",
+        "/team/123e4567-e89b-12d3-a456-426614174000/user/7?page=2&size=50&tag=a&tag=b&active=true&since=2024-01-02T03:04:05Z&timeout=1s", nil))
+    if bind != nil {
+        t.Fatal(bind)
+    }
+    if filter.ID != 7 || filter.Page.Page != 2 || *filter.Size != 50 || len(filter.Tag) != 2 || filter.Tag[1] != "b" ||
+        !filter.Active || filter.Since.Year() != 2024 || filter.Timeout != time.Second || filter.Ignored != "kept" ||
+        filter.Team.String() != "123e4567-e89b-12d3-a456-426614174000" {
+        t.Errorf("unexpected filter %+v", filter)
+    }
+
+    m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/team/nope/user/x?page=two&active=yes", nil))
+    var result BindError
+    if !errors.As(bind, &result) || len(result) != 4 {
+        t.Fatalf("expected 4 field errors got %v", bind)
+    }
+    if result[0].Field != "page" || result[0].Source != "query" || result[0].Value != "two" {
+        t.Errorf("unexpected field error %+v", result[0])
+    }
+    if status, _ := errorStatus(bind); status != http.StatusBadRequest {
+        t.Errorf("expected %d got %d", http.StatusBadRequest, status)
+    }
+    if err := Bind(httptest.NewRequest("GET", "/", nil), filter); err == nil {
+        t.Error("expected an error for a non pointer destination")
+    }
+}
@@ -0,0 +1,181 @@
+package router
+
+import "net"
+import "math"
+import "sync"
+import "time"
+import "strconv"
+import "net/http"
+import "hash/maphash"
+
+// Rate allows Limit requests per Period, with bursts of up to Limit requests.
+type Rate struct {
+    Limit  int
+    Period time.Duration
+}
+
+// LimitResult is the state of a bucket after a LimitStore took a token from it.
+type LimitResult struct {
+    Allowed    bool
+    Remaining  int
+    Reset      time.Duration
+    RetryAfter time.Duration
+}
+
+// LimitStore keeps the token buckets of a RateLimiter.
+type LimitStore interface {
+    // Take removes one token from the bucket of key if there is one.
+    Take(key string, rate Rate, now time.Time) LimitResult
+}
+
+// RateLimiter is a token bucket rate limiting middleware. Assign it with Use
+// on a Group, or With on a single route.
+type RateLimiter struct {
+    Rate Rate
+    // Key returns the bucket of a request, it defaults to KeyByIP.
+    Key func(*http.Request) string
+    // Store keeps the buckets, it defaults to a MemoryStore.
+    Store LimitStore
+    // Handler answers limited requests, it defaults to a plain 429.
+    Handler http.Handler
+
+    once sync.Once
+}
+
+// Middleware limits next to the rate of limiter. Every response carries the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers, limited
+// responses carry Retry-After as well. A Rate that is not positive does not
+// limit, as with MemoryStore, and next is returned as it is.
+func (limiter *RateLimiter) Middleware(next http.Handler) http.Handler {
+    if limiter.Rate.Limit <= 0 || limiter.Rate.Period <= 0 {
+        return next
+    }
+    limiter.once.Do(func() {
+        if limiter.Key == nil {
+            limiter.Key = KeyByIP
+        }
+        if limiter.Store == nil {
+            limiter.Store = NewMemoryStore()
+        }
+        if limiter.Handler == nil {
+            limiter.Handler = http.HandlerFunc(limitRequest)
+        }
+    })
+    policy := strconv.Itoa(limiter.Rate.Limit) + ";w=" + strconv.Itoa(seconds(limiter.Rate.Period))
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        result := limiter.Store.Take(limiter.Key(request), limiter.Rate, time.Now())
+        header := response.Header()
+        header.Set("RateLimit-Policy", policy)
+        header.Set("RateLimit-Limit", strconv.Itoa(limiter.Rate.Limit))
+        header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+        header.Set("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+        if !result.Allowed {
+            header.Set("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+            limiter.Handler.ServeHTTP(response, request)
+            return
+        }
+        next.ServeHTTP(response, request)
+    })
+}
+
+func limitRequest(response http.ResponseWriter, _ *http.Request) {
+    response.Header().Set("Content-Type", "text/html; charset=UTF-8")
+    response.WriteHeader(http.StatusTooManyRequests)
+    response.Write([]byte("429 - Too Many Requests"))
+}
+
+// seconds rounds duration up to whole seconds.
+func seconds(duration time.Duration) int {
+    return int(math.Ceil(duration.Seconds()))
+}
+
+// KeyByIP keys requests by the ip of the client connection.
+func KeyByIP(request *http.Request) string {
+    if host, _, exception := net.SplitHostPort(request.RemoteAddr); exception == nil {
+        return host
+    }
+    return request.RemoteAddr
+}
+
+// KeyByHeader keys requests by the value of header, for instance an api key.
+func KeyByHeader(header string) func(*http.Request) string {
+    return func(request *http.Request) string {
+        return request.Header.Get(header)
+    }
+}
+
+// KeyByRoute keys requests by method and matched route pattern, limiting a
+// route as a whole.
+func KeyByRoute(request *http.Request) string {
+    if route := CurrentRoute(request); route != nil {
+        return request.Method + " " + route.Pattern()
+    }
+    return request.Method
+}
+
+const storeShard = 64
+
+// MemoryStore is an in memory LimitStore sharded to reduce lock contention.
+type MemoryStore struct {
+    seed  maphash.Seed
+    shard [storeShard]limitShard
+}
+
+type limitShard struct {
+    lock   sync.Mutex
+    sweep  time.Time
+    bucket map[string]*limitBucket
+}
+
+type limitBucket struct {
+    token float64
+    last  time.Time
+    full  time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+    store := &MemoryStore{seed: maphash.MakeSeed()}
+    for index := range store.shard {
+        store.shard[index].bucket = make(map[string]*limitBucket)
+    }
+    return store
+}
+
+// Take removes one token from the bucket of key, a rate that is not positive
+// is unlimited.
+func (store *MemoryStore) Take(key string, rate Rate, now time.Time) LimitResult {
+    if rate.Limit <= 0 || rate.Period <= 0 {
+        return LimitResult{Allowed: true}
+    }
+    shard := &store.shard[maphash.String(store.seed, key)%storeShard]
+    shard.lock.Lock()
+    defer shard.lock.Unlock()
+    if now.Sub(shard.sweep) > rate.Period {
+        for name, bucket := range shard.bucket {
+            if now.After(bucket.full) {
+                delete(shard.bucket, name)
+            }
+        }
+        shard.sweep = now
+    }
+    limit := float64(rate.Limit)
+    refill := limit / rate.Period.Seconds()
+    bucket, ok := shard.bucket[key]
+    if !ok {
+        bucket = &limitBucket{token: limit, last: now}
+        shard.bucket[key] = bucket
+    }
+    bucket.token = math.Min(limit, bucket.token+now.Sub(bucket.last).Seconds()*refill)
+    bucket.last = now
+    var result LimitResult
+    if bucket.token >= 1 {
+        bucket.token--
+        result.Allowed = true
+    } else {
+        result.RetryAfter = time.Duration((1 - bucket.token) / refill * float64(time.Second))
+    }
+    result.Remaining = int(bucket.token)
+    result.Reset = time.Duration((limit - bucket.token) / refill * float64(time.Second))
+    bucket.full = now.Add(result.Reset)
+    return result
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestMemoryStore(t *testing.T) {
+    store := NewMemoryStore()
+    rate := Rate{Limit: 2, Period: 2 * time.Second}
+    now := time.Now()
+    sample := []struct {
+        after     time.Duration
+        allowed   bool
+        remaining int
+    }{
+        {0, true, 1},
+        {0, true, 0},
+        {0, false, 0},
+        {time.Second, true, 0},
+        {3 * time.Second, true, 1},
+    }
+    for index, v := range sample {
+        now = now.Add(v.after)
+        result := store.Take("client", rate, now)
+        if result.Allowed != v.allowed || result.Remaining != v.remaining {
+            t.Errorf("%d: expected %v %d got %+v", index, v.allowed, v.remaining, result)
+        }
+        if !result.Allowed && result.RetryAfter != time.Second {
+            t.Errorf("%d: expected retry after 1s got %s", index, result.RetryAfter)
+        }
+    }
+    if result := store.Take("other", rate, now); !result.Allowed {
+        t.Error("expected buckets to be independent")
+    }
+    for _, rate := range []Rate{{}, {Limit: 1}, {Period: time.Second}} {
+        if result := store.Take("client", rate, now); !result.Allowed {
+            t.Errorf("expected %+v to be unlimited got %+v", rate, result)
+        }
+    }
+}
+
+func TestRateLimiter_zeroRate(t *testing.T) {
+    m := New()
+    _ = m.With((&RateLimiter{}).Middleware).Get("/", func(_ http.ResponseWriter, _ *http.Request) {})
+    for range 3 {
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+        if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+            t.Fatalf("expected an unlimited %d got %d %q", http.StatusOK, w.Code, w.Header().Get("RateLimit-Limit"))
+        }
+    }
+}
+
+func TestRateLimiter(t *testing.T) {
+    limiter := &RateLimiter{Rate: Rate{Limit: 1, Period: time.Minute}, Key: KeyByHeader("X-Api-Key")}
+    m := New()
+    _ = m.With(limiter.Middleware).Get("/", func(_ http.ResponseWriter, _ *http.Request) {})
+    sample := []struct {
+        key        string
+        code       int
+        retryAfter string
+    }{
+        {"a", http.StatusOK, ""},
+        {"a", http.StatusTooManyRequests, "60"},
+        {"b", http.StatusOK, ""},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", "/", nil)
+        req.Header.Set("X-Api-Key", v.key)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code || w.Header().Get("Retry-After") != v.retryAfter {
+            t.Errorf("%s: expected %d %q got %d %q", v.key, v.code, v.retryAfter, w.Code, w.Header().Get("Retry-After"))
+        }
+        if w.Header().Get("RateLimit-Limit") != "1" || w.Header().Get("RateLimit-Remaining") != "0" {
+            t.Errorf("%s: unexpected headers %v", v.key, w.Header())
+        }
+    }
+}
+
+func TestKeyByRoute(t *testing.T) {
+    var key string
+    m := New()
+    _ = m.With(func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            key = KeyByRoute(r)
+            next.ServeHTTP(w, r)
+        })
+    }).Get("/user/:id", func(_ http.ResponseWriter, _ *http.Request) {})
+    m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/1", nil))
+    if key != "GET /user/:id" {
+        t.Errorf("expected GET /user/:id got %s", key)
+    }
+}
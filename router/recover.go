@@ -0,0 +1,92 @@
+package router
+
+import "fmt"
+import "log"
+import "net/http"
+import "runtime/debug"
+
+// Panic describes a panic recovered while serving a request.
+type Panic struct {
+    Value     any
+    Stack     []byte
+    Method    string
+    Path      string
+    Pattern   string
+    RequestID string
+}
+
+// Recovery turns panics of route handlers into a response instead of letting
+// net/http tear down the connection.
+type Recovery struct {
+    // Handler writes the response, it defaults to a plain 500.
+    Handler func(http.ResponseWriter, *http.Request, *Panic)
+    // Reporter receives every recovered panic, it defaults to the standard logger.
+    Reporter func(*http.Request, *Panic)
+}
+
+// Middleware wraps next with the recovery. http.ErrAbortHandler is panicked
+// again so net/http still aborts the response.
+func (recovery *Recovery) Middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        defer func() {
+            value := recover()
+            if value == nil {
+                return
+            }
+            if value == http.ErrAbortHandler {
+                panic(value)
+            }
+            stack := debug.Stack()
+            if carried, ok := value.(*stackPanic); ok {
+                value, stack = carried.value, carried.stack
+            }
+            event := &Panic{
+                Value:     value,
+                Stack:     stack,
+                Method:    request.Method,
+                Path:      request.URL.Path,
+                RequestID: RequestID(request),
+            }
+            if route := CurrentRoute(request); route != nil {
+                event.Pattern = route.Pattern()
+            }
+            report := recovery.Reporter
+            if report == nil {
+                report = reportPanic
+            }
+            report(request, event)
+            handler := recovery.Handler
+            if handler == nil {
+                handler = recoverPanic
+            }
+            handler(response, request, event)
+        }()
+        next.ServeHTTP(response, request)
+    })
+}
+
+// stackPanic carries a panic raised on another goroutine together with the
+// stack captured there, so recovering it reports the frames that panicked.
+type stackPanic struct {
+    value any
+    stack []byte
+}
+
+func (carried *stackPanic) String() string {
+    return fmt.Sprintf("%v\n%s", carried.value, carried.stack)
+}
+
+func reportPanic(_ *http.Request, event *Panic) {
+    log.Printf("router: panic serving %s %s (%s) [%s]: %v\n%s", event.Method, event.Path, event.Pattern, event.RequestID, event.Value, event.Stack)
+}
+
+func recoverPanic(response http.ResponseWriter, _ *http.Request, _ *Panic) {
+    response.Header().Set("Content-Type", "text/html; charset=UTF-8")
+    response.WriteHeader(http.StatusInternalServerError)
+    response.Write([]byte("500 - Internal Server Error"))
+}
+
+// Recover enables recovery around every route served by mux, nil disables it.
+func (mux *Mux) Recover(recovery *Recovery) {
+    mux.Router.recovery = recovery
+}
@@ -0,0 +1,48 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestMux_Recover(t *testing.T) {
+    var reported *Panic
+    m := New()
+    m.Recover(&Recovery{
+        Reporter: func(_ *http.Request, event *Panic) {
+            reported = event
+        },
+    })
+    _ = m.Get("/user/:id", func(_ http.ResponseWriter, _ *http.Request) {
+        panic("boom")
+    })
+    req := httptest.NewRequest("GET", "/user/1", nil)
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, req)
+    if w.Code != http.StatusInternalServerError {
+        t.Errorf("expected %d got %d", http.StatusInternalServerError, w.Code)
+    }
+    if reported == nil {
+        t.Fatal("expected the panic to be reported")
+    }
+    if reported.Value != "boom" || reported.Pattern != "/user/:id" || reported.Path != "/user/1" {
+        t.Errorf("unexpected report %+v", reported)
+    }
+    if !strings.Contains(string(reported.Stack), "recover_test.go") {
+        t.Error("expected the stack to contain the panicking handler")
+    }
+}
+
+func TestRecovery_abort(t *testing.T) {
+    handler := (&Recovery{}).Middleware(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+        panic(http.ErrAbortHandler)
+    }))
+    defer func() {
+        if value := recover(); value != http.ErrAbortHandler {
+            t.Errorf("expected ErrAbortHandler got %v", value)
+        }
+    }()
+    handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
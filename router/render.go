@@ -0,0 +1,91 @@
+package router
+
+import "io"
+import "mime"
+import "errors"
+import "strconv"
+import "strings"
+import "net/http"
+import "encoding/json"
+
+// DecodeLimit is the largest body DecodeJSON reads.
+var DecodeLimit int64 = 1 << 20
+
+// DecodeJSON decodes the json body of request into destination. The body must
+// be declared as application/json, hold a single value no larger than
+// DecodeLimit and only contain fields known to destination. Failures are
+// reported as *HTTPError with status 400, 413 or 415.
+func DecodeJSON(request *http.Request, destination any) error {
+    media, _, exception := mime.ParseMediaType(request.Header.Get("Content-Type"))
+    if exception != nil || media != "application/json" && !strings.HasSuffix(media, "+json") {
+        return NewHTTPError(http.StatusUnsupportedMediaType, "content type must be application/json")
+    }
+    if request.Body == nil {
+        return NewHTTPError(http.StatusBadRequest, "request body is empty")
+    }
+    decoder := json.NewDecoder(http.MaxBytesReader(nil, request.Body, DecodeLimit))
+    decoder.DisallowUnknownFields()
+    if exception = decoder.Decode(destination); exception != nil {
+        return decodeError(exception)
+    }
+    if _, exception = decoder.Token(); !errors.Is(exception, io.EOF) {
+        return NewHTTPError(http.StatusBadRequest, "request body must hold a single json value")
+    }
+    return nil
+}
+
+// decodeError converts a json decoding failure into an HTTPError.
+func decodeError(exception error) error {
+    var syntax *json.SyntaxError
+    var kind *json.UnmarshalTypeError
+    var overflow *http.MaxBytesError
+    switch {
+    case errors.As(exception, &overflow):
+        return WrapHTTPError(http.StatusRequestEntityTooLarge, exception)
+    case errors.As(exception, &syntax):
+        return &HTTPError{Status: http.StatusBadRequest, Message: "malformed json at offset " + strconv.FormatInt(syntax.Offset, 10), Err: exception}
+    case errors.As(exception, &kind):
+        return &HTTPError{Status: http.StatusBadRequest, Message: "invalid value for field " + kind.Field, Err: exception}
+    case errors.Is(exception, io.EOF):
+        return &HTTPError{Status: http.StatusBadRequest, Message: "request body is empty", Err: exception}
+    case errors.Is(exception, io.ErrUnexpectedEOF):
+        return &HTTPError{Status: http.StatusBadRequest, Message: "malformed json", Err: exception}
+    case strings.HasPrefix(exception.Error(), "json: unknown field "):
+        return &HTTPError{Status: http.StatusBadRequest, Message: strings.TrimPrefix(exception.Error(), "json: "), Err: exception}
+    }
+    return WrapHTTPError(http.StatusBadRequest, exception)
+}
+
+// JSON writes value as json with status. Nothing is written when value fails
+// to encode.
+func JSON(response http.ResponseWriter, status int, value any) error {
+    data, exception := json.Marshal(value)
+    if exception != nil {
+        return WrapHTTPError(http.StatusInternalServerError, exception)
+    }
+    return write(response, status, "application/json; charset=utf-8", append(data, '\n'))
+}
+
+// Text writes value as plain text with status.
+func Text(response http.ResponseWriter, status int, value string) error {
+    return write(response, status, "text/plain; charset=utf-8", []byte(value))
+}
+
+// HTML writes value as html with status.
+func HTML(response http.ResponseWriter, status int, value string) error {
+    return write(response, status, "text/html; charset=utf-8", []byte(value))
+}
+
+// NoContent answers with 204 and no body.
+func NoContent(response http.ResponseWriter) error {
+    response.WriteHeader(http.StatusNoContent)
+    return nil
+}
+
+func write(response http.ResponseWriter, status int, media string, data []byte) error {
+    response.Header().Set("Content-Type", media)
+    response.Header().Set("Content-Length", strconv.Itoa(len(data)))
+    response.WriteHeader(status)
+    _, exception := response.Write(data)
+    return exception
+}
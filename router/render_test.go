@@ -0,0 +1,74 @@
+package router
+
+import (
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+    type User struct {
+        Name string `json:"name"`
+        Age  int    `json:"age"`
+    }
+    sample := []struct {
+        media, body string
+        status      int
+    }{
+        {"application/json", `{"name":"alien","age":3}`, 0},
+        {"application/merge-patch+json; charset=utf-8", `{"name":"alien"}`, 0},
+        {"text/plain", `{"name":"alien"}`, http.StatusUnsupportedMediaType},
+        {"application/json", `{"name":"alien","admin":true}`, http.StatusBadRequest},
+        {"application/json", `{"name":"alien"} {"name":"other"}`, http.StatusBadRequest},
+        {"application/json", `{"age":"three"}`, http.StatusBadRequest},
+        {"application/json", `{"name":`, http.StatusBadRequest},
+        {"application/json", `{"name"}`, http.StatusBadRequest},
+        {"application/json", ``, http.StatusBadRequest},
+        {"application/json", `{"name":"` + strings.Repeat("a", int(DecodeLimit)) + `"}`, http.StatusRequestEntityTooLarge},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+        req.Header.Set("Content-Type", v.media)
+        var user User
+        err := DecodeJSON(req, &user)
+        var exception *HTTPError
+        if v.status == 0 {
+            if err != nil || user.Name != "alien" {
+                t.Errorf("%.40s: unexpected %+v %v", v.body, user, err)
+            }
+            continue
+        }
+        if !errors.As(err, &exception) || exception.Status != v.status {
+            t.Errorf("%.40s: expected %d got %v", v.body, v.status, err)
+        }
+    }
+}
+
+func TestRender(t *testing.T) {
+    sample := []struct {
+        render func(http.ResponseWriter) error
+        status int
+        media  string
+        body   string
+    }{
+        {func(w http.ResponseWriter) error { return JSON(w, http.StatusCreated, map[string]int{"id": 1}) }, http.StatusCreated, "application/json; charset=utf-8", "{\"id\":1}\n"},
+        {func(w http.ResponseWriter) error { return Text(w, http.StatusOK, "hello") }, http.StatusOK, "text/plain; charset=utf-8", "hello"},
+        {func(w http.ResponseWriter) error { return HTML(w, http.StatusOK, "<p>hello</p>") }, http.StatusOK, "text/html; charset=utf-8", "<p>hello</p>"},
+        {NoContent, http.StatusNoContent, "", ""},
+    }
+    for _, v := range sample {
+        w := httptest.NewRecorder()
+        if err := v.render(w); err != nil {
+            t.Fatal(err)
+        }
+        if w.Code != v.status || w.Header().Get("Content-Type") != v.media || w.Body.String() != v.body {
+            t.Errorf("expected %d %s %s got %d %s %s", v.status, v.media, v.body, w.Code, w.Header().Get("Content-Type"), w.Body)
+        }
+    }
+    w := httptest.NewRecorder()
+    if err := JSON(w, http.StatusOK, make(chan int)); err == nil || w.Body.Len() != 0 {
+        t.Errorf("expected an error and no body got %v %s", err, w.Body)
+    }
+}
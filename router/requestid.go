@@ -0,0 +1,70 @@
+package router
+
+import "context"
+import "net/http"
+import "crypto/rand"
+import "encoding/hex"
+
+// RequestIdentifier propagates a request id, taken from the request header or
+// generated when missing.
+type RequestIdentifier struct {
+    // Header carries the id in both directions, it defaults to X-Request-ID.
+    Header string
+    // Generate returns a new id, it defaults to 16 random bytes in hex.
+    Generate func() string
+}
+
+// Middleware stores the id of request in its context and echoes it in the
+// response header. Incoming ids longer than 128 bytes or containing anything
+// but printable ascii are replaced.
+func (identifier *RequestIdentifier) Middleware(next http.Handler) http.Handler {
+    header := identifier.Header
+    if header == "" {
+        header = "X-Request-ID"
+    }
+    generate := identifier.Generate
+    if generate == nil {
+        generate = generateRequestID
+    }
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        value := request.Header.Get(header)
+        if !validRequestID(value) {
+            value = generate()
+        }
+        if state := stateOf(request); state != nil {
+            state.requestID = value
+        }
+        response.Header().Set(header, value)
+        next.ServeHTTP(response, request.WithContext(context.WithValue(request.Context(), keyRequestID, value)))
+    })
+}
+
+// RequestID returns the id assigned to request by a RequestIdentifier, an
+// empty string when there is none.
+func RequestID(request *http.Request) string {
+    if value, ok := request.Context().Value(keyRequestID).(string); ok {
+        return value
+    }
+    if state := stateOf(request); state != nil {
+        return state.requestID
+    }
+    return ""
+}
+
+func validRequestID(value string) bool {
+    if value == "" || len(value) > 128 {
+        return false
+    }
+    for index := 0; index < len(value); index++ {
+        if value[index] <= ' ' || value[index] > '~' {
+            return false
+        }
+    }
+    return true
+}
+
+func generateRequestID() string {
+    data := make([]byte, 16)
+    rand.Read(data)
+    return hex.EncodeToString(data)
+}
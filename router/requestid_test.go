@@ -0,0 +1,37 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestRequestIdentifier(t *testing.T) {
+    var seen string
+    m := New()
+    m.UseGlobal((&RequestIdentifier{Header: "X-Trace", Generate: func() string { return "generated" }}).Middleware)
+    _ = m.Get("/", func(_ http.ResponseWriter, r *http.Request) {
+        seen = RequestID(r)
+    })
+    sample := []struct {
+        incoming, expect string
+    }{
+        {"", "generated"},
+        {"abc-123", "abc-123"},
+        {"evil\nvalue", "generated"},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", "/", nil)
+        if v.incoming != "" {
+            req.Header.Set("X-Trace", v.incoming)
+        }
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if seen != v.expect || w.Header().Get("X-Trace") != v.expect {
+            t.Errorf("%q: expected %s got %s %s", v.incoming, v.expect, seen, w.Header().Get("X-Trace"))
+        }
+    }
+    if len(generateRequestID()) != 32 {
+        t.Error("expected a 32 character id")
+    }
+}
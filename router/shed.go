@@ -0,0 +1,115 @@
+package router
+
+import "sync"
+import "time"
+import "strconv"
+import "net/http"
+import "sync/atomic"
+
+// Shedder limits the number of requests a Group serves concurrently. Requests
+// over Limit wait in a queue of at most Queue requests for up to Wait, the
+// others are shed with 503 and Retry-After. Assign it with Use on a Group.
+type Shedder struct {
+    Limit int
+    Queue int
+    Wait  time.Duration
+    // RetryAfter is announced to shed clients, it defaults to one second.
+    RetryAfter time.Duration
+    // Handler answers shed requests, it defaults to a plain 503.
+    Handler http.Handler
+
+    once   sync.Once
+    slot   chan struct{}
+    queue  chan struct{}
+    lock   Lock
+    flight map[string]*atomic.Int64
+}
+
+func (shedder *Shedder) init() {
+    shedder.once.Do(func() {
+        shedder.slot = make(chan struct{}, max(shedder.Limit, 1))
+        shedder.queue = make(chan struct{}, max(shedder.Queue, 0))
+        shedder.flight = make(map[string]*atomic.Int64)
+        if shedder.RetryAfter <= 0 {
+            shedder.RetryAfter = time.Second
+        }
+        if shedder.Handler == nil {
+            shedder.Handler = http.HandlerFunc(expireRequest)
+        }
+    })
+}
+
+// acquire takes a slot, waiting in the queue when there is room for it.
+func (shedder *Shedder) acquire(request *http.Request) bool {
+    select {
+    case shedder.slot <- struct{}{}:
+        return true
+    default:
+    }
+    select {
+    case shedder.queue <- struct{}{}:
+    default:
+        return false
+    }
+    defer func() { <-shedder.queue }()
+    timer := time.NewTimer(shedder.Wait)
+    defer timer.Stop()
+    select {
+    case shedder.slot <- struct{}{}:
+        return true
+    case <-timer.C:
+        return false
+    case <-request.Context().Done():
+        return false
+    }
+}
+
+// gauge returns the in flight counter of pattern.
+func (shedder *Shedder) gauge(pattern string) *atomic.Int64 {
+    shedder.lock.RLock()
+    value, ok := shedder.flight[pattern]
+    shedder.lock.RUnlock()
+    if ok {
+        return value
+    }
+    shedder.lock.Lock()
+    defer shedder.lock.Unlock()
+    if value, ok = shedder.flight[pattern]; !ok {
+        value = &atomic.Int64{}
+        shedder.flight[pattern] = value
+    }
+    return value
+}
+
+// Middleware sheds the requests to next exceeding the limits of shedder.
+func (shedder *Shedder) Middleware(next http.Handler) http.Handler {
+    shedder.init()
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        if !shedder.acquire(request) {
+            response.Header().Set("Retry-After", strconv.Itoa(seconds(shedder.RetryAfter)))
+            shedder.Handler.ServeHTTP(response, request)
+            return
+        }
+        defer func() { <-shedder.slot }()
+        var pattern string
+        if route := CurrentRoute(request); route != nil {
+            pattern = route.Pattern()
+        }
+        gauge := shedder.gauge(pattern)
+        gauge.Add(1)
+        defer gauge.Add(-1)
+        next.ServeHTTP(response, request)
+    })
+}
+
+// InFlight returns the number of requests being served per route pattern.
+func (shedder *Shedder) InFlight() map[string]int64 {
+    shedder.init()
+    shedder.lock.RLock()
+    defer shedder.lock.RUnlock()
+    result := make(map[string]int64, len(shedder.flight))
+    for pattern, value := range shedder.flight {
+        result[pattern] = value.Load()
+    }
+    return result
+}
@@ -0,0 +1,59 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestShedder(t *testing.T) {
+    shedder := &Shedder{Limit: 1, Queue: 1, Wait: 50 * time.Millisecond}
+    entered := make(chan struct{})
+    release := make(chan struct{})
+    m := New()
+    expensive := m.Group("/report")
+    expensive.Use(shedder.Middleware)
+    _ = expensive.Get("/:id", func(_ http.ResponseWriter, _ *http.Request) {
+        entered <- struct{}{}
+        <-release
+    })
+
+    var group sync.WaitGroup
+    group.Add(1)
+    go func() {
+        defer group.Done()
+        m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/report/1", nil))
+    }()
+    <-entered
+    if flight := shedder.InFlight()["/report/:id"]; flight != 1 {
+        t.Errorf("expected 1 in flight got %d", flight)
+    }
+
+    // waits in the queue and times out
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, httptest.NewRequest("GET", "/report/2", nil))
+    if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+        t.Errorf("expected 503 with Retry-After got %d %v", w.Code, w.Header())
+    }
+
+    // waits in the queue and gets the slot
+    group.Add(1)
+    go func() {
+        defer group.Done()
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, httptest.NewRequest("GET", "/report/3", nil))
+        if w.Code != http.StatusOK {
+            t.Errorf("expected %d got %d", http.StatusOK, w.Code)
+        }
+    }()
+    time.Sleep(time.Millisecond)
+    release <- struct{}{}
+    <-entered
+    release <- struct{}{}
+    group.Wait()
+    if flight := shedder.InFlight()["/report/:id"]; flight != 0 {
+        t.Errorf("expected 0 in flight got %d", flight)
+    }
+}
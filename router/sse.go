@@ -0,0 +1,149 @@
+package router
+
+import "sync"
+import "time"
+import "errors"
+import "context"
+import "strconv"
+import "strings"
+import "net/http"
+
+// SSEHeartbeat is the interval between the comments an EventStream sends to
+// keep idle connections open, 0 disables them.
+var SSEHeartbeat = 15 * time.Second
+
+// ErrorStreamClosed is returned by an EventStream once the client is gone or
+// the stream was closed.
+var ErrorStreamClosed = errors.New("event stream closed")
+
+// EventStream is a Server-Sent Events response.
+type EventStream struct {
+    lock       sync.Mutex
+    response   http.ResponseWriter
+    controller *http.ResponseController
+    context    context.Context
+    last       string
+    closed     bool
+    stop       chan struct{}
+    done       chan struct{}
+}
+
+// SSE starts a Server-Sent Events response on response, for instance
+//   m.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+//       stream, err := SSE(w, r)
+//       if err != nil {
+//           return
+//       }
+//       defer stream.Close()
+//       for message := range subscribe(stream.LastEventID()) {
+//           if stream.Send("message", message.ID, message.Text) != nil {
+//               return
+//           }
+//       }
+//   })
+// It fails when response cannot be flushed, like under Timeout which buffers
+// responses, whatever middlewares of this package wrap it. The stream sends a heartbeat every SSEHeartbeat and ends when the
+// request context is done.
+func SSE(response http.ResponseWriter, request *http.Request) (*EventStream, error) {
+    controller := http.NewResponseController(response)
+    header := response.Header()
+    header.Set("Content-Type", "text/event-stream")
+    header.Set("Cache-Control", "no-cache")
+    header.Set("X-Accel-Buffering", "no")
+    header.Del("Content-Length")
+    response.WriteHeader(http.StatusOK)
+    if exception := controller.Flush(); exception != nil {
+        return nil, exception
+    }
+    stream := &EventStream{
+        response:   response,
+        controller: controller,
+        context:    request.Context(),
+        last:       request.Header.Get("Last-Event-ID"),
+        stop:       make(chan struct{}),
+        done:       make(chan struct{}),
+    }
+    go stream.heartbeat(SSEHeartbeat)
+    return stream, nil
+}
+
+// LastEventID returns the Last-Event-ID the client resumes from, an empty
+// string on its first connection.
+func (stream *EventStream) LastEventID() string {
+    return stream.last
+}
+
+// Done is closed when the client disconnects.
+func (stream *EventStream) Done() <-chan struct{} {
+    return stream.context.Done()
+}
+
+// Send writes an event and flushes it. event and id are left out when empty,
+// data spanning several lines is sent as several data fields.
+func (stream *EventStream) Send(event, id, data string) error {
+    if strings.ContainsAny(event, "\r\n") || strings.ContainsAny(id, "\r\n\x00") {
+        return errors.New("event and id must hold a single line")
+    }
+    var builder strings.Builder
+    if event != "" {
+        builder.WriteString("event: " + event + "\n")
+    }
+    if id != "" {
+        builder.WriteString("id: " + id + "\n")
+    }
+    data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\r", "\n")
+    for _, line := range strings.Split(data, "\n") {
+        builder.WriteString("data: " + line + "\n")
+    }
+    builder.WriteString("\n")
+    return stream.write(builder.String())
+}
+
+// Retry asks the client to wait delay before reconnecting.
+func (stream *EventStream) Retry(delay time.Duration) error {
+    return stream.write("retry: " + strconv.FormatInt(delay.Milliseconds(), 10) + "\n\n")
+}
+
+// Close stops the heartbeat, the handler must not return before calling it.
+func (stream *EventStream) Close() {
+    stream.lock.Lock()
+    if !stream.closed {
+        stream.closed = true
+        close(stream.stop)
+    }
+    stream.lock.Unlock()
+    <-stream.done
+}
+
+func (stream *EventStream) write(value string) error {
+    stream.lock.Lock()
+    defer stream.lock.Unlock()
+    if stream.closed || stream.context.Err() != nil {
+        return ErrorStreamClosed
+    }
+    if _, exception := stream.response.Write([]byte(value)); exception != nil {
+        return exception
+    }
+    return stream.controller.Flush()
+}
+
+func (stream *EventStream) heartbeat(interval time.Duration) {
+    defer close(stream.done)
+    if interval <= 0 {
+        return
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ticker.C:
+            if stream.write(":\n\n") != nil {
+                return
+            }
+        case <-stream.stop:
+            return
+        case <-stream.context.Done():
+            return
+        }
+    }
+}
@@ -0,0 +1,119 @@
+package router
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestSSE(t *testing.T) {
+    heartbeat := SSEHeartbeat
+    SSEHeartbeat = 10 * time.Millisecond
+    defer func() { SSEHeartbeat = heartbeat }()
+    m := New()
+    m.UseGlobal(AccessLog(slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))), (&RequestIdentifier{}).Middleware, (&Metrics{}).Middleware)
+    m.Use((&Compressor{}).Middleware, (&ETagger{}).Middleware)
+    finished := make(chan error, 1)
+    _ = m.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+        stream, err := SSE(w, r)
+        if err != nil {
+            finished <- err
+            return
+        }
+        defer stream.Close()
+        if err = stream.Send("greeting", "1", "hello\nworld"); err == nil {
+            err = stream.Send("", "", "resumed from "+stream.LastEventID())
+        }
+        if err != nil {
+            finished <- err
+            return
+        }
+        <-stream.Done()
+        for err == nil {
+            err = stream.Send("", "", "gone")
+        }
+        finished <- err
+    })
+    server := httptest.NewServer(m)
+    defer server.Close()
+    req, _ := http.NewRequest("GET", server.URL+"/events", nil)
+    req.Header.Set("Accept-Encoding", "gzip")
+    req.Header.Set("Last-Event-ID", "7")
+    res, err := http.DefaultTransport.RoundTrip(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if res.Header.Get("Content-Type") != "text/event-stream" || res.Header.Get("Content-Encoding") != "" {
+        t.Errorf("unexpected header %v", res.Header)
+    }
+    reader := bufio.NewReader(res.Body)
+    var lines []string
+    for len(lines) < 7 {
+        line, err := reader.ReadString('\n')
+        if err != nil {
+            t.Fatal(err)
+        }
+        lines = append(lines, line)
+    }
+    expected := "event: greeting\nid: 1\ndata: hello\ndata: world\n\ndata: resumed from 7\n\n"
+    if strings.Join(lines, "") != expected {
+        t.Errorf("expected %q got %q", expected, strings.Join(lines, ""))
+    }
+    if line, err := reader.ReadString('\n'); err != nil || line != ":\n" {
+        t.Errorf("expected a heartbeat got %q %v", line, err)
+    }
+    res.Body.Close()
+    select {
+    case err := <-finished:
+        if !errors.Is(err, ErrorStreamClosed) {
+            t.Errorf("expected %v got %v", ErrorStreamClosed, err)
+        }
+    case <-time.After(time.Second):
+        t.Fatal("handler did not see the client disconnect")
+    }
+}
+
+func TestSSE_Unsupported(t *testing.T) {
+    m := New()
+    var result error
+    _ = m.Timeout(time.Second).Get("/events", func(w http.ResponseWriter, r *http.Request) {
+        _, result = SSE(w, r)
+    })
+    m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/events", nil))
+    if !errors.Is(result, http.ErrNotSupported) {
+        t.Errorf("expected %v got %v", http.ErrNotSupported, result)
+    }
+    wrapper := []Middleware{
+        AccessLog(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))),
+        (&Compressor{}).Middleware,
+        (&ETagger{}).Middleware,
+    }
+    for index, middleware := range wrapper {
+        result = nil
+        m := New()
+        _ = m.Timeout(time.Second).With(middleware).Get("/events", func(w http.ResponseWriter, r *http.Request) {
+            _, result = SSE(w, r)
+        })
+        m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/events", nil))
+        if !errors.Is(result, http.ErrNotSupported) {
+            t.Errorf("wrapper %d: expected %v got %v", index, http.ErrNotSupported, result)
+        }
+    }
+    stream, err := SSE(httptest.NewRecorder(), httptest.NewRequest("GET", "/events", nil))
+    if err != nil {
+        t.Fatal(err)
+    }
+    stream.Close()
+    if err = stream.Send("", "", "late"); !errors.Is(err, ErrorStreamClosed) {
+        t.Errorf("expected %v got %v", ErrorStreamClosed, err)
+    }
+    if err = stream.Send("bad\nevent", "", "data"); err == nil {
+        t.Error("expected an error for a multi line event")
+    }
+}
@@ -0,0 +1,330 @@
+package router
+
+import "io"
+import "fmt"
+import "html"
+import "mime"
+import "path"
+import "sort"
+import "sync"
+import "time"
+import "bytes"
+import "io/fs"
+import "strings"
+import "net/url"
+import "net/http"
+import "crypto/sha256"
+import "encoding/hex"
+
+// StaticOption configures Static.
+type StaticOption struct {
+    // Index is served for directories, it defaults to index.html.
+    Index string
+    // Listing lists directories without an index instead of answering 404.
+    Listing bool
+    // Hash derives ETags from the file content instead of its size and
+    // modification time. Files without a modification time, like those of an
+    // embed.FS, are always hashed.
+    Hash bool
+    // MaxAge sets the Cache-Control max-age of the served files.
+    MaxAge time.Duration
+}
+
+// precompressed lists the siblings Static looks for, by preference.
+var precompressed = []struct {
+    name      string
+    extension string
+}{
+    {"br", ".br"},
+    {"gzip", ".gz"},
+}
+
+type static struct {
+    mux      *Mux
+    point    string
+    fsys     fs.FS
+    option   StaticOption
+    hash     sync.Map
+    // fallback answers the requests matching no file instead of the not
+    // found handler.
+    fallback http.Handler
+}
+
+// Static serves the files of fsys under prefix for GET and HEAD, for
+// instance
+//   //go:embed assets
+//   var assets embed.FS
+//   sub, _ := fs.Sub(assets, "assets")
+//   m.Static("/assets", sub)
+// Responses carry an ETag and Last-Modified, honor If-None-Match,
+// If-Modified-Since and Range, and use a .br or .gz sibling of the file when
+// the client accepts it. Paths escaping fsys are rejected.
+func (mux *Mux) Static(prefix string, fsys fs.FS, option ...StaticOption) error {
+    handler := &static{mux: mux, point: path.Join(mux.prefix, prefix), fsys: fsys}
+    if len(option) > 0 {
+        handler.option = option[0]
+    }
+    if handler.option.Index == "" {
+        handler.option.Index = "index.html"
+    }
+    for _, method := range []string{http.MethodGet, http.MethodHead} {
+        if exception := mux.Handle(method, prefix, handler); exception != nil {
+            return exception
+        }
+        if exception := mux.Handle(method, path.Join(prefix, "*"), handler); exception != nil {
+            return exception
+        }
+    }
+    return nil
+}
+
+// resolve returns the name of the file request asks for, false when the path
+// is not a valid name inside fsys.
+func (handler *static) resolve(request *http.Request) (string, bool) {
+    name := strings.TrimPrefix(path.Clean(request.URL.Path), handler.point)
+    if strings.ContainsAny(name, "\\\x00") {
+        return "", false
+    }
+    name = strings.TrimPrefix(path.Clean("/"+name), "/")
+    if name == "" {
+        name = "."
+    }
+    return name, fs.ValidPath(name)
+}
+
+func (handler *static) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+    name, ok := handler.resolve(request)
+    if !ok {
+        handler.notFound(response, request)
+        return
+    }
+    info, exception := fs.Stat(handler.fsys, name)
+    if exception != nil {
+        handler.notFound(response, request)
+        return
+    }
+    if info.IsDir() {
+        if !strings.HasSuffix(request.URL.Path, "/") {
+            target := request.URL.Path + "/"
+            if request.URL.RawQuery != "" {
+                target += "?" + request.URL.RawQuery
+            }
+            http.Redirect(response, request, target, http.StatusMovedPermanently)
+            return
+        }
+        index := path.Join(name, handler.option.Index)
+        if value, exception := fs.Stat(handler.fsys, index); exception == nil && value.Mode().IsRegular() {
+            handler.serveFile(response, request, index, value)
+            return
+        }
+        if handler.option.Listing {
+            handler.serveListing(response, request, name)
+            return
+        }
+        handler.notFound(response, request)
+        return
+    }
+    if !info.Mode().IsRegular() {
+        handler.notFound(response, request)
+        return
+    }
+    handler.serveFile(response, request, name, info)
+}
+
+func (handler *static) notFound(response http.ResponseWriter, request *http.Request) {
+    if handler.fallback != nil {
+        handler.fallback.ServeHTTP(response, request)
+        return
+    }
+    var host *hostRoute
+    if hosts := handler.mux.matchHost(request.Host); len(hosts) > 0 {
+        host = hosts[0].host
+    }
+    handler.mux.missingHandler(host, path.Clean(request.URL.Path)).ServeHTTP(response, request)
+}
+
+func (handler *static) serveFile(response http.ResponseWriter, request *http.Request, name string, info fs.FileInfo) {
+    header := response.Header()
+    media := mime.TypeByExtension(path.Ext(name))
+    served, suffix := name, ""
+    for _, value := range precompressed {
+        sibling, exception := fs.Stat(handler.fsys, name+value.extension)
+        if exception != nil || !sibling.Mode().IsRegular() {
+            continue
+        }
+        vary(header, "Accept-Encoding")
+        if acceptEncoding(request.Header.Get("Accept-Encoding"), value.name) {
+            served, suffix, info = name+value.extension, "-"+value.name, sibling
+            header.Set("Content-Encoding", value.name)
+            if media == "" {
+                media = "application/octet-stream"
+            }
+            break
+        }
+    }
+    if media != "" {
+        header.Set("Content-Type", media)
+    }
+    content, exception := handler.open(served)
+    if exception != nil {
+        handler.notFound(response, request)
+        return
+    }
+    if closer, ok := content.(io.Closer); ok {
+        defer closer.Close()
+    }
+    tag, exception := handler.etag(served, info, content)
+    if exception != nil {
+        http.Error(response, "500 - Internal Server Error", http.StatusInternalServerError)
+        return
+    }
+    header.Set("ETag", `"`+tag+suffix+`"`)
+    if handler.option.MaxAge > 0 {
+        header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(handler.option.MaxAge.Seconds())))
+    }
+    http.ServeContent(response, request, name, info.ModTime(), content)
+}
+
+// open returns a seekable reader on name.
+func (handler *static) open(name string) (io.ReadSeeker, error) {
+    file, exception := handler.fsys.Open(name)
+    if exception != nil {
+        return nil, exception
+    }
+    if seeker, ok := file.(io.ReadSeeker); ok {
+        return seeker, nil
+    }
+    defer file.Close()
+    data, exception := io.ReadAll(file)
+    if exception != nil {
+        return nil, exception
+    }
+    return bytes.NewReader(data), nil
+}
+
+type staticHash struct {
+    size    int64
+    modtime time.Time
+    tag     string
+}
+
+// etag returns the entity tag of the file name, rewinding content when it had
+// to be read.
+func (handler *static) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+    if !handler.option.Hash && !info.ModTime().IsZero() {
+        return fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()), nil
+    }
+    if value, ok := handler.hash.Load(name); ok {
+        cache := value.(staticHash)
+        if cache.size == info.Size() && cache.modtime.Equal(info.ModTime()) {
+            return cache.tag, nil
+        }
+    }
+    digest := sha256.New()
+    if _, exception := io.Copy(digest, content); exception != nil {
+        return "", exception
+    }
+    if _, exception := content.Seek(0, io.SeekStart); exception != nil {
+        return "", exception
+    }
+    tag := hex.EncodeToString(digest.Sum(nil)[:16])
+    handler.hash.Store(name, staticHash{size: info.Size(), modtime: info.ModTime(), tag: tag})
+    return tag, nil
+}
+
+func (handler *static) serveListing(response http.ResponseWriter, request *http.Request, name string) {
+    list, exception := fs.ReadDir(handler.fsys, name)
+    if exception != nil {
+        handler.notFound(response, request)
+        return
+    }
+    sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+    var buffer bytes.Buffer
+    buffer.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+    for _, value := range list {
+        entry := value.Name()
+        if value.IsDir() {
+            entry += "/"
+        }
+        link := url.URL{Path: entry}
+        fmt.Fprintf(&buffer, "<a href=\"%s\">%s</a>\n", html.EscapeString(link.String()), html.EscapeString(entry))
+    }
+    buffer.WriteString("</pre>\n")
+    response.Header().Set("Content-Type", "text/html; charset=utf-8")
+    if request.Method != http.MethodHead {
+        response.Write(buffer.Bytes())
+    }
+}
+
+// acceptEncoding reports whether an Accept-Encoding header accepts coding.
+func acceptEncoding(header, coding string) bool {
+    var wildcard float64 = -1
+    for _, value := range parseAccept(header) {
+        switch value.media {
+        case coding:
+            return value.quality > 0
+        case "*":
+            wildcard = value.quality
+        }
+    }
+    return wildcard > 0
+}
+
+// SPA serves the single page application of fsys under prefix. It is the
+// not found handler of the prefix Group, so Routes under prefix still win.
+// GET and HEAD requests are served the file they name like Static, or index
+// when no file matches and the request accepts html. Other requests, and the
+// requests under one of the exclude prefixes relative to prefix, keep the not
+// found handler in place, so
+//   m.SPA("/app", sub, "index.html", "/api")
+//   m.Group("/app/api").Get("/user", user)
+// answers /app/settings with index.html but /app/api/missing with a 404.
+func (mux *Mux) SPA(prefix string, fsys fs.FS, index string, exclude ...string) error {
+    if _, exception := fs.Stat(fsys, index); exception != nil {
+        return exception
+    }
+    group := mux.Group(prefix)
+    missing := group.missingHandler(group.host, group.prefix)
+    excluded := make([]string, len(exclude))
+    for position, value := range exclude {
+        excluded[position] = path.Join(group.prefix, value)
+    }
+    handler := &static{mux: group, point: group.prefix, fsys: fsys, option: StaticOption{Index: index}}
+    handler.fallback = http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        info, exception := fs.Stat(fsys, index)
+        if exception != nil || !info.Mode().IsRegular() || !acceptHTML(request.Header.Get("Accept")) {
+            missing.ServeHTTP(response, request)
+            return
+        }
+        response.Header().Set("Cache-Control", "no-cache")
+        handler.serveFile(response, request, index, info)
+    })
+    group.NotFoundHandler(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        if request.Method != http.MethodGet && request.Method != http.MethodHead || underPrefix(path.Clean(request.URL.Path), excluded) {
+            missing.ServeHTTP(response, request)
+            return
+        }
+        handler.ServeHTTP(response, request)
+    }))
+    return nil
+}
+
+// underPrefix reports whether url is one of list or lies under one of them.
+func underPrefix(url string, list []string) bool {
+    for _, prefix := range list {
+        if url == prefix || strings.HasPrefix(url, strings.TrimSuffix(prefix, "/")+"/") {
+            return true
+        }
+    }
+    return false
+}
+
+// acceptHTML reports whether an Accept header names html explicitly.
+func acceptHTML(header string) bool {
+    for _, value := range parseAccept(header) {
+        if (value.media == "text/html" || value.media == "application/xhtml+xml") && value.quality > 0 {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,138 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "testing/fstest"
+    "time"
+)
+
+func TestMux_Static(t *testing.T) {
+    modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+    fsys := fstest.MapFS{
+        "index.html":         {Data: []byte("<p>home</p>"), ModTime: modified},
+        "app.js":             {Data: []byte("console.log('hello world')"), ModTime: modified},
+        "app.js.gz":          {Data: []byte("gzip bytes"), ModTime: modified},
+        "app.js.br":          {Data: []byte("brotli bytes"), ModTime: modified},
+        "docs/readme.txt":    {Data: []byte("read me"), ModTime: modified},
+        "embedded/plain.css": {Data: []byte("body{}")},
+    }
+    m := New()
+    _ = m.Static("/assets", fsys, StaticOption{MaxAge: time.Hour})
+    _ = m.Group("/browse").Static("/", fsys, StaticOption{Listing: true})
+
+    sample := []struct {
+        path, header, value string
+        code                int
+        body, encoding      string
+    }{
+        {"/assets/app.js", "", "", http.StatusOK, "console.log('hello world')", ""},
+        {"/assets/app.js", "Accept-Encoding", "gzip, deflate", http.StatusOK, "gzip bytes", "gzip"},
+        {"/assets/app.js", "Accept-Encoding", "gzip;q=0.5, br", http.StatusOK, "brotli bytes", "br"},
+        {"/assets/app.js", "Accept-Encoding", "br;q=0, *", http.StatusOK, "gzip bytes", "gzip"},
+        {"/assets/app.js", "Range", "bytes=0-6", http.StatusPartialContent, "console", ""},
+        {"/assets/app.js", "If-Modified-Since", modified.Format(http.TimeFormat), http.StatusNotModified, "", ""},
+        {"/assets/", "", "", http.StatusOK, "<p>home</p>", ""},
+        {"/assets", "", "", http.StatusMovedPermanently, "", ""},
+        {"/assets/docs/", "", "", http.StatusNotFound, "404 - Not Found", ""},
+        {"/assets/missing.js", "", "", http.StatusNotFound, "404 - Not Found", ""},
+        {"/assets/../alien.go", "", "", http.StatusNotFound, "404 - Not Found", ""},
+        {"/assets/embedded/plain.css", "", "", http.StatusOK, "body{}", ""},
+        {"/browse/docs/", "", "", http.StatusOK, "<a href=\"readme.txt\">readme.txt</a>", ""},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", v.path, nil)
+        if v.header != "" {
+            req.Header.Set(v.header, v.value)
+        }
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code || !strings.Contains(w.Body.String(), v.body) || w.Header().Get("Content-Encoding") != v.encoding {
+            t.Errorf("%s %s: expected %d %q %q got %d %q %q", v.path, v.value, v.code, v.body, v.encoding, w.Code, w.Body, w.Header().Get("Content-Encoding"))
+        }
+        if list := w.Header().Values("Vary"); len(list) > 1 {
+            t.Errorf("%s %s: expected a single Vary got %q", v.path, v.value, list)
+        }
+    }
+}
+
+func TestMux_StaticETag(t *testing.T) {
+    fsys := fstest.MapFS{
+        "app.js":    {Data: []byte("hello"), ModTime: time.Now()},
+        "app.js.gz": {Data: []byte("zipped"), ModTime: time.Now()},
+        "plain.css": {Data: []byte("body{}")},
+    }
+    m := New()
+    _ = m.Static("/", fsys)
+    for _, v := range []struct {
+        path, encoding string
+    }{
+        {"/app.js", ""},
+        {"/app.js", "gzip"},
+        {"/plain.css", ""},
+    } {
+        req := httptest.NewRequest("GET", v.path, nil)
+        req.Header.Set("Accept-Encoding", v.encoding)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        tag := w.Header().Get("ETag")
+        if tag == "" || strings.HasSuffix(tag, `-gzip"`) != (v.encoding == "gzip") {
+            t.Fatalf("%s %s: unexpected ETag %q", v.path, v.encoding, tag)
+        }
+        if v.encoding != "" && !strings.Contains(w.Header().Get("Vary"), "Accept-Encoding") {
+            t.Errorf("%s: expected Vary: Accept-Encoding", v.path)
+        }
+        req = httptest.NewRequest("GET", v.path, nil)
+        req.Header.Set("Accept-Encoding", v.encoding)
+        req.Header.Set("If-None-Match", tag)
+        w = httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != http.StatusNotModified {
+            t.Errorf("%s %s: expected %d got %d", v.path, v.encoding, http.StatusNotModified, w.Code)
+        }
+    }
+}
+
+func TestMux_SPA(t *testing.T) {
+    fsys := fstest.MapFS{
+        "index.html": {Data: []byte("<div id=app></div>"), ModTime: time.Now()},
+        "main.js":    {Data: []byte("mount()"), ModTime: time.Now()},
+    }
+    m := New()
+    if err := m.SPA("/app", fsys, "index.html", "/api"); err != nil {
+        t.Fatal(err)
+    }
+    _ = m.Group("/app/api").Get("/user", func(w http.ResponseWriter, r *http.Request) {
+        _, _ = w.Write([]byte("user"))
+    })
+    html := "text/html,application/xhtml+xml,*/*;q=0.8"
+    sample := []struct {
+        method, path, accept string
+        code                 int
+        body                 string
+    }{
+        {"GET", "/app/main.js", "*/*", http.StatusOK, "mount()"},
+        {"GET", "/app/", html, http.StatusOK, "<div id=app></div>"},
+        {"GET", "/app/settings/profile", html, http.StatusOK, "<div id=app></div>"},
+        {"HEAD", "/app/settings", html, http.StatusOK, ""},
+        {"GET", "/app/api/user", html, http.StatusOK, "user"},
+        {"GET", "/app/api/missing", "application/json", http.StatusNotFound, "404 - Not Found"},
+        {"GET", "/app/api/missing", html, http.StatusNotFound, "404 - Not Found"},
+        {"GET", "/app/api", html, http.StatusNotFound, "404 - Not Found"},
+        {"GET", "/app/apis", html, http.StatusOK, "<div id=app></div>"},
+        {"GET", "/app/missing.js", "*/*", http.StatusNotFound, "404 - Not Found"},
+        {"POST", "/app/settings", html, http.StatusNotFound, "404 - Not Found"},
+        {"GET", "/other", html, http.StatusNotFound, "404 - Not Found"},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest(v.method, v.path, nil)
+        req.Header.Set("Accept", v.accept)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code || w.Body.String() != v.body {
+            t.Errorf("%s %s %s: expected %d %q got %d %q", v.method, v.path, v.accept, v.code, v.body, w.Code, w.Body)
+        }
+    }
+}
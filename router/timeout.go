@@ -0,0 +1,120 @@
+package router
+
+import "log"
+import "sync"
+import "time"
+import "bytes"
+import "context"
+import "errors"
+import "net/http"
+import "runtime/debug"
+
+// timeoutWriter buffers the response of a handler running under a deadline
+// so that writes racing the deadline never reach the client.
+type timeoutWriter struct {
+    lock    sync.Mutex
+    header  http.Header
+    buffer  bytes.Buffer
+    status  int
+    expired bool
+}
+
+func (writer *timeoutWriter) Header() http.Header {
+    return writer.header
+}
+
+func (writer *timeoutWriter) WriteHeader(status int) {
+    writer.lock.Lock()
+    defer writer.lock.Unlock()
+    if writer.expired || writer.status != 0 || status < http.StatusOK {
+        return
+    }
+    writer.status = status
+}
+
+func (writer *timeoutWriter) Write(data []byte) (int, error) {
+    writer.lock.Lock()
+    defer writer.lock.Unlock()
+    if writer.expired {
+        return 0, http.ErrHandlerTimeout
+    }
+    if writer.status == 0 {
+        writer.status = http.StatusOK
+    }
+    return writer.buffer.Write(data)
+}
+
+// serveTimeout serves request with handler under the deadline of route. The
+// response is buffered and only sent when handler returns in time, otherwise
+// the expired handler of route answers.
+func (route *Route) serveTimeout(handler http.Handler, response http.ResponseWriter, request *http.Request) {
+    ctx, cancel := context.WithTimeout(request.Context(), route.timeout)
+    defer cancel()
+    request = request.WithContext(ctx)
+    writer := &timeoutWriter{header: make(http.Header)}
+    done := make(chan struct{})
+    panicked := make(chan any, 1)
+    go func() {
+        defer func() {
+            if value := recover(); value != nil {
+                if value != http.ErrAbortHandler {
+                    value = &stackPanic{value: value, stack: debug.Stack()}
+                }
+                panicked <- value
+            }
+        }()
+        handler.ServeHTTP(writer, request)
+        close(done)
+    }()
+    select {
+    case value := <-panicked:
+        panic(value)
+    case <-done:
+        writer.lock.Lock()
+        defer writer.lock.Unlock()
+        header := response.Header()
+        for key, value := range writer.header {
+            header[key] = value
+        }
+        if writer.status == 0 {
+            writer.status = http.StatusOK
+        }
+        response.WriteHeader(writer.status)
+        response.Write(writer.buffer.Bytes())
+    case <-ctx.Done():
+        writer.lock.Lock()
+        writer.expired = true
+        writer.lock.Unlock()
+        if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+            return
+        }
+        log.Printf("router: %s %s (%s) timed out after %s", request.Method, request.URL.Path, route.path, route.timeout)
+        expired := route.expired
+        if expired == nil {
+            expired = http.HandlerFunc(expireRequest)
+        }
+        expired.ServeHTTP(response, request)
+    }
+}
+
+func expireRequest(response http.ResponseWriter, _ *http.Request) {
+    response.Header().Set("Content-Type", "text/html; charset=UTF-8")
+    response.WriteHeader(http.StatusServiceUnavailable)
+    response.Write([]byte("503 - Service Unavailable"))
+}
+
+// Timeout returns a Mux whose routes are cancelled after duration. Their
+// response is buffered, a handler still running at the deadline has its
+// context cancelled and the client receives a 503, or the response of the
+// optional handler. Applied to a Group it covers every route of the group
+//   reports := m.Group("/reports").Timeout(30 * time.Second)
+// and a single route otherwise
+//   m.Timeout(time.Second).Get("/health", myHandler)
+func (mux *Mux) Timeout(duration time.Duration, handler ...http.Handler) *Mux {
+    derived := mux.derive()
+    derived.timeout = duration
+    if len(handler) > 0 {
+        derived.expired = handler[0]
+    }
+    return derived
+}
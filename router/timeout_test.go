@@ -0,0 +1,74 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestMux_Timeout(t *testing.T) {
+    release := make(chan struct{})
+    finished := make(chan struct{})
+    m := New()
+    slow := m.Group("/slow").Timeout(20 * time.Millisecond)
+    _ = slow.Get("/stuck", func(w http.ResponseWriter, r *http.Request) {
+        <-r.Context().Done()
+        <-release
+        _, err := w.Write([]byte("late"))
+        if err != http.ErrHandlerTimeout {
+            t.Errorf("expected ErrHandlerTimeout got %v", err)
+        }
+        close(finished)
+    })
+    _ = slow.Get("/fast", func(w http.ResponseWriter, _ *http.Request) {
+        w.Header().Set("X-Fast", "yes")
+        w.WriteHeader(http.StatusAccepted)
+        _, _ = w.Write([]byte("fast"))
+    })
+    custom := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+        w.WriteHeader(http.StatusGatewayTimeout)
+    })
+    _ = m.Timeout(time.Millisecond, custom).Get("/custom", func(_ http.ResponseWriter, r *http.Request) {
+        <-r.Context().Done()
+    })
+
+    sample := []struct {
+        path, body string
+        code       int
+    }{
+        {"/slow/stuck", "503 - Service Unavailable", http.StatusServiceUnavailable},
+        {"/slow/fast", "fast", http.StatusAccepted},
+        {"/custom", "", http.StatusGatewayTimeout},
+    }
+    for _, v := range sample {
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, httptest.NewRequest("GET", v.path, nil))
+        if w.Code != v.code || w.Body.String() != v.body {
+            t.Errorf("%s: expected %d %s got %d %s", v.path, v.code, v.body, w.Code, w.Body)
+        }
+    }
+    close(release)
+    <-finished
+}
+
+func TestMux_TimeoutPanic(t *testing.T) {
+    var reported *Panic
+    m := New()
+    m.Recover(&Recovery{Reporter: func(_ *http.Request, event *Panic) { reported = event }})
+    _ = m.Timeout(time.Second).Get("/", func(_ http.ResponseWriter, _ *http.Request) {
+        panic("boom")
+    })
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+    if w.Code != http.StatusInternalServerError {
+        t.Errorf("expected %d got %d", http.StatusInternalServerError, w.Code)
+    }
+    if reported == nil || reported.Value != "boom" {
+        t.Fatalf("unexpected report %+v", reported)
+    }
+    if !strings.Contains(string(reported.Stack), "TestMux_TimeoutPanic.func") {
+        t.Errorf("expected the stack to contain the panicking handler got\n%s", reported.Stack)
+    }
+}
@@ -0,0 +1,175 @@
+package router
+
+import "sync"
+import "context"
+import "strings"
+import "net/http"
+import "crypto/rand"
+import "encoding/hex"
+
+// SpanContext identifies a span as propagated by the W3C traceparent and
+// tracestate headers.
+type SpanContext struct {
+    TraceID [16]byte
+    SpanID  [8]byte
+    Flags   byte
+    State   string
+    Remote  bool
+}
+
+// Valid reports whether both ids of span are set.
+func (span SpanContext) Valid() bool {
+    return span.TraceID != [16]byte{} && span.SpanID != [8]byte{}
+}
+
+// TraceParent formats span as a traceparent header value.
+func (span SpanContext) TraceParent() string {
+    return "00-" + hex.EncodeToString(span.TraceID[:]) + "-" + hex.EncodeToString(span.SpanID[:]) + "-" + hex.EncodeToString([]byte{span.Flags})
+}
+
+// ParseTraceParent parses the traceparent header value of the W3C Trace Context.
+func ParseTraceParent(value string) (SpanContext, bool) {
+    var result SpanContext
+    part := strings.Split(strings.TrimSpace(value), "-")
+    if len(part) < 4 || len(part[0]) != 2 || part[0] == "ff" || part[0] == "00" && len(part) != 4 {
+        return result, false
+    }
+    if len(part[1]) != 32 || len(part[2]) != 16 || len(part[3]) != 2 {
+        return result, false
+    }
+    if _, exception := hex.Decode(result.TraceID[:], []byte(part[1])); exception != nil {
+        return result, false
+    }
+    if _, exception := hex.Decode(result.SpanID[:], []byte(part[2])); exception != nil {
+        return result, false
+    }
+    var flags [1]byte
+    if _, exception := hex.Decode(flags[:], []byte(part[3])); exception != nil {
+        return result, false
+    }
+    result.Flags = flags[0]
+    result.Remote = true
+    return result, result.Valid()
+}
+
+// Span is the part of a tracing span the router records into.
+type Span interface {
+    SetName(name string)
+    SetAttribute(key string, value any)
+    SetError(description string)
+    End()
+}
+
+// Tracer starts spans. Adapters of the OpenTelemetry SDK implement it outside
+// of this package, MemoryTracer records spans for tests.
+type Tracer interface {
+    // Start starts a span named name, child of parent when parent is valid.
+    Start(ctx context.Context, name string, parent SpanContext) (context.Context, Span)
+}
+
+// Tracing creates a span per request. Assign Middleware with UseGlobal so
+// unmatched requests are traced too.
+type Tracing struct {
+    Tracer Tracer
+}
+
+// Middleware traces the requests served by next. The span is named after the
+// method and the matched route pattern, carries the standard http attributes
+// and continues the trace of the traceparent and tracestate headers.
+func (tracing *Tracing) Middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        parent, _ := ParseTraceParent(request.Header.Get("traceparent"))
+        if parent.Valid() {
+            parent.State = request.Header.Get("tracestate")
+        }
+        ctx, span := tracing.Tracer.Start(request.Context(), request.Method, parent)
+        defer span.End()
+        scheme := "http"
+        if request.TLS != nil {
+            scheme = "https"
+        }
+        span.SetAttribute("http.request.method", request.Method)
+        span.SetAttribute("url.path", request.URL.Path)
+        span.SetAttribute("url.scheme", scheme)
+        span.SetAttribute("server.address", requestHost(request.Host))
+        span.SetAttribute("client.address", KeyByIP(request))
+        span.SetAttribute("user_agent.original", request.UserAgent())
+        writer := newResponseWriter(response)
+        request = request.WithContext(ctx)
+        next.ServeHTTP(writer, request)
+        status := writer.Status()
+        span.SetAttribute("http.response.status_code", status)
+        if route := CurrentRoute(request); route != nil {
+            span.SetName(request.Method + " " + route.Pattern())
+            span.SetAttribute("http.route", route.Pattern())
+        } else if state := stateOf(request); state != nil && state.outcome != 0 {
+            span.SetAttribute("router.outcome", state.outcome)
+        }
+        if status >= http.StatusInternalServerError {
+            span.SetError(http.StatusText(status))
+        }
+    })
+}
+
+// MemoryTracer is a Tracer keeping its spans in memory.
+type MemoryTracer struct {
+    lock Lock
+    span []*MemorySpan
+}
+
+// MemorySpan is a span recorded by a MemoryTracer.
+type MemorySpan struct {
+    lock      sync.Mutex
+    Name      string
+    Context   SpanContext
+    Parent    SpanContext
+    Attribute map[string]any
+    Error     string
+    Ended     bool
+}
+
+func (tracer *MemoryTracer) Start(ctx context.Context, name string, parent SpanContext) (context.Context, Span) {
+    span := &MemorySpan{Name: name, Parent: parent, Attribute: make(map[string]any)}
+    span.Context.TraceID = parent.TraceID
+    if !parent.Valid() {
+        rand.Read(span.Context.TraceID[:])
+    }
+    rand.Read(span.Context.SpanID[:])
+    span.Context.Flags = parent.Flags
+    span.Context.State = parent.State
+    tracer.lock.Lock()
+    defer tracer.lock.Unlock()
+    tracer.span = append(tracer.span, span)
+    return ctx, span
+}
+
+// Spans returns the spans started so far.
+func (tracer *MemoryTracer) Spans() []*MemorySpan {
+    tracer.lock.RLock()
+    defer tracer.lock.RUnlock()
+    return append([]*MemorySpan(nil), tracer.span...)
+}
+
+func (span *MemorySpan) SetName(name string) {
+    span.lock.Lock()
+    defer span.lock.Unlock()
+    span.Name = name
+}
+
+func (span *MemorySpan) SetAttribute(key string, value any) {
+    span.lock.Lock()
+    defer span.lock.Unlock()
+    span.Attribute[key] = value
+}
+
+func (span *MemorySpan) SetError(description string) {
+    span.lock.Lock()
+    defer span.lock.Unlock()
+    span.Error = description
+}
+
+func (span *MemorySpan) End() {
+    span.lock.Lock()
+    defer span.lock.Unlock()
+    span.Ended = true
+}
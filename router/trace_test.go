@@ -0,0 +1,70 @@
+package router
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+    sample := []struct {
+        value string
+        ok    bool
+    }{
+        {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+        {"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", true},
+        {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+        {"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+        {"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+        {"00-4bf92f3577b34da6a3ce929d0e0e4736-zzf067aa0ba902b7-01", false},
+        {"", false},
+    }
+    for _, v := range sample {
+        span, ok := ParseTraceParent(v.value)
+        if ok != v.ok {
+            t.Errorf("%s: expected %v got %v", v.value, v.ok, ok)
+        }
+        if ok && v.value[:2] == "00" && span.TraceParent() != v.value {
+            t.Errorf("expected %s got %s", v.value, span.TraceParent())
+        }
+    }
+}
+
+func TestTracing(t *testing.T) {
+    tracer := &MemoryTracer{}
+    m := New()
+    m.UseGlobal((&Tracing{Tracer: tracer}).Middleware)
+    _ = m.Get("/user/:id", func(_ http.ResponseWriter, _ *http.Request) {})
+    _ = m.Post("/fail", func(w http.ResponseWriter, _ *http.Request) {
+        w.WriteHeader(http.StatusBadGateway)
+    })
+
+    req := httptest.NewRequest("GET", "/user/1", nil)
+    req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+    req.Header.Set("tracestate", "vendor=value")
+    m.ServeHTTP(httptest.NewRecorder(), req)
+    m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+    m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+    m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/fail", nil))
+
+    span := tracer.Spans()
+    if len(span) != 4 {
+        t.Fatalf("expected 4 spans got %d", len(span))
+    }
+    found := span[0]
+    if found.Name != "GET /user/:id" || found.Attribute["http.route"] != "/user/:id" || !found.Ended {
+        t.Errorf("unexpected span %+v", found)
+    }
+    if found.Context.TraceParent()[3:35] != "4bf92f3577b34da6a3ce929d0e0e4736" || found.Context.State != "vendor=value" {
+        t.Errorf("expected the trace to continue got %s %s", found.Context.TraceParent(), found.Context.State)
+    }
+    if span[1].Name != "GET" || span[1].Attribute["router.outcome"] != http.StatusNotFound {
+        t.Errorf("unexpected span %+v", span[1])
+    }
+    if span[2].Attribute["router.outcome"] != http.StatusMethodNotAllowed {
+        t.Errorf("unexpected span %+v", span[2])
+    }
+    if span[3].Error != "Bad Gateway" || span[3].Attribute["http.response.status_code"] != http.StatusBadGateway {
+        t.Errorf("unexpected span %+v", span[3])
+    }
+}
@@ -0,0 +1,246 @@
+package router
+
+import "fmt"
+import "sync"
+import "errors"
+import "reflect"
+import "strconv"
+import "strings"
+import "net/mail"
+import "encoding/json"
+
+// ValidationError lists the fields failing their validate tag.
+type ValidationError []*FieldError
+
+func (exception ValidationError) Error() string {
+    list := make([]string, 0, len(exception))
+    for _, value := range exception {
+        list = append(list, value.Error())
+    }
+    return strings.Join(list, "; ")
+}
+
+// MarshalJSON renders the error as the field, source and message it concerns.
+func (exception *FieldError) MarshalJSON() ([]byte, error) {
+    return json.Marshal(struct {
+        Field   string `json:"field"`
+        Source  string `json:"source,omitempty"`
+        Message string `json:"message"`
+    }{exception.Field, exception.Source, exception.Err.Error()})
+}
+
+// Validate checks the fields of the struct value points to against their
+// validate tags. Rules are separated by commas
+//   type User struct {
+//       ID    int    `path:"id" validate:"required,min=1"`
+//       Page  int    `query:"page" validate:"max=100"`
+//       Email string `json:"email" validate:"required,email"`
+//       Role  string `json:"role" validate:"oneof=admin member"`
+//   }
+// min and max bound numbers by value and strings, slices and maps by length.
+// Fields are reported under their path, query or json name with the matching
+// source, so a struct filled by Bind and DecodeJSON is validated at once.
+// Nested structs are validated too. The tags of a type are checked the first
+// time it is validated, an unknown rule or a rule that does not apply to its
+// field panics.
+func Validate(value any) error {
+    data := reflect.ValueOf(value)
+    for data.Kind() == reflect.Pointer && !data.IsNil() {
+        data = data.Elem()
+    }
+    if data.Kind() != reflect.Struct {
+        return errors.New("validate value must be a struct")
+    }
+    var result ValidationError
+    validateStruct(data, "", &result)
+    if len(result) > 0 {
+        return result
+    }
+    return nil
+}
+
+// fieldName returns the name and source a field is reported with.
+func fieldName(field reflect.StructField) (string, string) {
+    for _, source := range []string{"path", "query", "json"} {
+        name, _, _ := strings.Cut(field.Tag.Get(source), ",")
+        if name != "" && name != "-" {
+            if source == "json" {
+                source = "body"
+            }
+            return name, source
+        }
+    }
+    return field.Name, ""
+}
+
+// rule is a single parsed rule of a validate tag.
+type rule struct {
+    name     string
+    argument string
+    bound    float64
+    option   []string
+}
+
+// fieldRule holds what Validate checks on a struct field.
+type fieldRule struct {
+    index    int
+    name     string
+    source   string
+    embedded bool
+    rule     []rule
+}
+
+// typeRule caches the parsed fieldRule of every validated struct type.
+var typeRule sync.Map
+
+func rulesOf(kind reflect.Type) []fieldRule {
+    if value, ok := typeRule.Load(kind); ok {
+        return value.([]fieldRule)
+    }
+    var result []fieldRule
+    for index := 0; index < kind.NumField(); index++ {
+        field := kind.Field(index)
+        if !field.IsExported() {
+            continue
+        }
+        if field.Anonymous && field.Type.Kind() == reflect.Struct {
+            result = append(result, fieldRule{index: index, embedded: true})
+            continue
+        }
+        name, source := fieldName(field)
+        list, exception := parseRule(field.Type, field.Tag.Get("validate"))
+        if exception != nil {
+            panic(fmt.Sprintf("router: invalid validate tag on %s.%s: %v", kind, field.Name, exception))
+        }
+        result = append(result, fieldRule{index: index, name: name, source: source, rule: list})
+    }
+    value, _ := typeRule.LoadOrStore(kind, result)
+    return value.([]fieldRule)
+}
+
+// parseRule parses the validate tag of a field of type kind.
+func parseRule(kind reflect.Type, tag string) ([]rule, error) {
+    if tag == "" || tag == "-" {
+        return nil, nil
+    }
+    for kind.Kind() == reflect.Pointer {
+        kind = kind.Elem()
+    }
+    var result []rule
+    for _, item := range strings.Split(tag, ",") {
+        name, argument, _ := strings.Cut(strings.TrimSpace(item), "=")
+        value := rule{name: name, argument: argument}
+        switch name {
+        case "required":
+        case "min", "max":
+            bound, exception := strconv.ParseFloat(argument, 64)
+            if exception != nil {
+                return nil, errors.New("invalid rule " + name + "=" + argument)
+            }
+            if _, _, ok := measureOf(reflect.Zero(kind)); !ok {
+                return nil, errors.New("rule " + name + " does not apply to " + kind.String())
+            }
+            value.bound = bound
+        case "email":
+            if kind.Kind() != reflect.String {
+                return nil, errors.New("rule email does not apply to " + kind.String())
+            }
+        case "oneof":
+            value.option = strings.Fields(argument)
+            if len(value.option) == 0 {
+                return nil, errors.New("rule oneof lists no value")
+            }
+        default:
+            return nil, errors.New("unknown rule " + name)
+        }
+        result = append(result, value)
+    }
+    return result, nil
+}
+
+func validateStruct(value reflect.Value, prefix string, result *ValidationError) {
+    for _, field := range rulesOf(value.Type()) {
+        data := value.Field(field.index)
+        if field.embedded {
+            validateStruct(data, prefix, result)
+            continue
+        }
+        name := prefix + field.name
+        exception := validateField(data, field.rule)
+        for data.Kind() == reflect.Pointer && !data.IsNil() {
+            data = data.Elem()
+        }
+        if exception != nil {
+            *result = append(*result, &FieldError{Field: name, Source: field.source, Value: fmt.Sprint(data.Interface()), Err: exception})
+            continue
+        }
+        if data.Kind() == reflect.Struct && data.Type() != typeTime {
+            validateStruct(data, name+".", result)
+        }
+    }
+}
+
+func validateField(value reflect.Value, list []rule) error {
+    for _, item := range list {
+        if item.name == "required" {
+            if value.IsZero() {
+                return errors.New("is required")
+            }
+            continue
+        }
+        if value.Kind() == reflect.Pointer {
+            if value.IsNil() {
+                return nil
+            }
+            value = value.Elem()
+        }
+        if exception := validateRule(value, item); exception != nil {
+            return exception
+        }
+    }
+    return nil
+}
+
+func validateRule(value reflect.Value, item rule) error {
+    switch item.name {
+    case "min", "max":
+        measure, unit, _ := measureOf(value)
+        if item.name == "min" && measure < item.bound {
+            return errors.New("must be at least " + item.argument + unit)
+        }
+        if item.name == "max" && measure > item.bound {
+            return errors.New("must be at most " + item.argument + unit)
+        }
+    case "email":
+        address, exception := mail.ParseAddress(value.String())
+        if exception != nil || address.Address != value.String() {
+            return errors.New("must be a valid email address")
+        }
+    case "oneof":
+        data := fmt.Sprint(value.Interface())
+        for _, option := range item.option {
+            if option == data {
+                return nil
+            }
+        }
+        return errors.New("must be one of " + item.argument)
+    }
+    return nil
+}
+
+// measureOf returns what min and max compare for value.
+func measureOf(value reflect.Value) (float64, string, bool) {
+    switch value.Kind() {
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+        return float64(value.Int()), "", true
+    case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        return float64(value.Uint()), "", true
+    case reflect.Float32, reflect.Float64:
+        return value.Float(), "", true
+    case reflect.String:
+        return float64(len([]rune(value.String()))), " characters", true
+    case reflect.Slice, reflect.Array, reflect.Map:
+        return float64(value.Len()), " items", true
+    }
+    return 0, "", false
+}
@@ -0,0 +1,130 @@
+package router
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestValidate(t *testing.T) {
+    type Address struct {
+        City string `json:"city" validate:"required"`
+    }
+    type User struct {
+        ID      int      `path:"id" validate:"required,min=1"`
+        Page    int      `query:"page" validate:"max=100"`
+        Email   string   `json:"email" validate:"required,email"`
+        Role    string   `json:"role" validate:"oneof=admin member"`
+        Name    string   `json:"name" validate:"min=2,max=5"`
+        Tag     []string `json:"tag" validate:"max=2"`
+        Age     *int     `json:"age" validate:"min=18"`
+        Address Address  `json:"address"`
+    }
+    age := 16
+    sample := []struct {
+        user   User
+        fields string
+    }{
+        {User{ID: 1, Page: 1, Email: "a@b.co", Role: "admin", Name: "alien", Address: Address{City: "x"}}, ""},
+        {User{Page: 101, Email: "Alien <a@b.co>", Role: "root", Name: "a", Tag: []string{"a", "b", "c"}, Age: &age},
+            "path id,query page,body email,body role,body name,body tag,body age,body address.city"},
+    }
+    for _, v := range sample {
+        err := Validate(&v.user)
+        if v.fields == "" {
+            if err != nil {
+                t.Errorf("unexpected error %v", err)
+            }
+            continue
+        }
+        var result ValidationError
+        if !errors.As(err, &result) {
+            t.Fatalf("expected a ValidationError got %v", err)
+        }
+        var fields []string
+        for _, field := range result {
+            fields = append(fields, field.Source+" "+field.Field)
+        }
+        if strings.Join(fields, ",") != v.fields {
+            t.Errorf("expected %s got %s", v.fields, strings.Join(fields, ","))
+        }
+        if result[0].Err.Error() != "is required" || result[1].Err.Error() != "must be at most 100" {
+            t.Errorf("unexpected messages %v", result)
+        }
+    }
+    if err := Validate(1); err == nil {
+        t.Error("expected an error for a non struct value")
+    }
+}
+
+func TestValidate_invalidTag(t *testing.T) {
+    sample := []struct {
+        name  string
+        value any
+    }{
+        {"unknown", &struct {
+            Name string `validate:"requried"`
+        }{}},
+        {"argument", &struct {
+            Age int `validate:"min=abc"`
+        }{}},
+        {"kind", &struct {
+            Admin bool `validate:"max=1"`
+        }{}},
+        {"email", &struct {
+            Email int `validate:"email"`
+        }{}},
+        {"oneof", &struct {
+            Role string `validate:"oneof="`
+        }{}},
+    }
+    for _, v := range sample {
+        func() {
+            defer func() {
+                if value := recover(); value == nil || !strings.Contains(fmt.Sprint(value), "invalid validate tag") {
+                    t.Errorf("%s: expected a panic got %v", v.name, value)
+                }
+            }()
+            _ = Validate(v.value)
+        }()
+    }
+}
+
+func TestValidate_problem(t *testing.T) {
+    type Query struct {
+        Page int `query:"page" validate:"min=1"`
+    }
+    m := New()
+    m.ErrorHandler(ProblemJSON)
+    _ = m.HandleError("GET", "/users", func(_ http.ResponseWriter, r *http.Request) error {
+        var query Query
+        if err := Bind(r, &query); err != nil {
+            return err
+        }
+        return Validate(&query)
+    })
+    w := httptest.NewRecorder()
+    m.ServeHTTP(w, httptest.NewRequest("GET", "/users?page=0", nil))
+    if w.Code != http.StatusUnprocessableEntity {
+        t.Errorf("expected %d got %d", http.StatusUnprocessableEntity, w.Code)
+    }
+    var document struct {
+        Status int `json:"status"`
+        Errors []struct {
+            Field   string `json:"field"`
+            Source  string `json:"source"`
+            Message string `json:"message"`
+        } `json:"errors"`
+    }
+    if err := json.NewDecoder(w.Body).Decode(&document); err != nil {
+        t.Fatal(err)
+    }
+    if document.Status != http.StatusUnprocessableEntity || len(document.Errors) != 1 ||
+        document.Errors[0].Field != "page" || document.Errors[0].Source != "query" || document.Errors[0].Message != "must be at least 1" {
+        t.Errorf("unexpected document %+v", document)
+    }
+}
@@ -0,0 +1,38 @@
+package router
+
+import "net/http"
+import "github.com/zaoangod/tiny/router/ws"
+
+// WebSocketHandler serves an upgraded WebSocket connection, request is the
+// upgrade request so GetParameter returns the path parameters of the route.
+type WebSocketHandler = func(conn *ws.Conn, request *http.Request)
+
+// WebSocket registers handler for the WebSocket upgrades requested with GET on
+// pattern, for instance
+//   m.WebSocket("/ws/:room", func(conn *ws.Conn, r *http.Request) {
+//       room := GetParameter(r).Get("room")
+//       for {
+//           kind, data, err := conn.ReadMessage()
+//           if err != nil {
+//               return
+//           }
+//           conn.WriteMessage(kind, data)
+//       }
+//   })
+// The handshake is done by upgrader, a default ws.Upgrader when missing,
+// which answers failed handshakes itself. The connection is closed with
+// ws.CloseNormal once handler returns.
+func (mux *Mux) WebSocket(pattern string, handler WebSocketHandler, upgrader ...*ws.Upgrader) error {
+    value := &ws.Upgrader{}
+    if len(upgrader) > 0 && upgrader[0] != nil {
+        value = upgrader[0]
+    }
+    return mux.HandleGet(pattern, http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        conn, exception := value.Upgrade(response, request)
+        if exception != nil {
+            return
+        }
+        defer conn.Close(ws.CloseNormal, "")
+        handler(conn, request)
+    }))
+}
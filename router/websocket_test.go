@@ -0,0 +1,89 @@
+package router
+
+import (
+    "bufio"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/zaoangod/tiny/router/ws"
+)
+
+func TestMux_WebSocket(t *testing.T) {
+    m := New()
+    m.UseGlobal((&RequestIdentifier{}).Middleware)
+    m.Use((&Compressor{}).Middleware)
+    status := make(chan int, 1)
+    m.UseGlobal(func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            writer := newResponseWriter(w)
+            next.ServeHTTP(writer, r)
+            status <- writer.Status()
+        })
+    })
+    _ = m.WebSocket("/ws/:room", func(conn *ws.Conn, r *http.Request) {
+        _, data, err := conn.ReadMessage()
+        if err != nil {
+            return
+        }
+        _ = conn.WriteMessage(ws.TextMessage, []byte(GetParameter(r).Get("room")+": "+string(data)))
+    })
+    server := httptest.NewServer(m)
+    defer server.Close()
+    conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer conn.Close()
+    conn.SetDeadline(time.Now().Add(5 * time.Second))
+    _, _ = io.WriteString(conn, "GET /ws/lobby HTTP/1.1\r\nHost: example.com\r\nOrigin: http://example.com\r\n"+
+        "Connection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+    reader := bufio.NewReader(conn)
+    res, err := http.ReadResponse(reader, nil)
+    if err != nil || res.StatusCode != http.StatusSwitchingProtocols || res.Header.Get("X-Request-ID") == "" {
+        t.Fatalf("unexpected handshake %v %v", res, err)
+    }
+    // a masked text frame holding "hi"
+    _, _ = conn.Write([]byte{0x81, 0x82, 0, 0, 0, 0, 'h', 'i'})
+    frame := make([]byte, 11)
+    if _, err = io.ReadFull(reader, frame); err != nil || string(frame) != "\x81\x09lobby: hi" {
+        t.Errorf("unexpected frame %q %v", frame, err)
+    }
+    frame = make([]byte, 4)
+    if _, err = io.ReadFull(reader, frame); err != nil || string(frame) != "\x88\x02\x03\xe8" {
+        t.Errorf("expected a normal close got %q %v", frame, err)
+    }
+    if value := <-status; value != http.StatusSwitchingProtocols {
+        t.Errorf("expected status %d got %d", http.StatusSwitchingProtocols, value)
+    }
+}
+
+func TestMux_WebSocketReject(t *testing.T) {
+    m := New()
+    _ = m.WebSocket("/ws", func(conn *ws.Conn, r *http.Request) {})
+    _ = m.Timeout(time.Second).WebSocket("/slow", func(conn *ws.Conn, r *http.Request) {})
+    sample := []struct {
+        path, origin string
+        code         int
+    }{
+        {"/ws", "http://evil.example.com", http.StatusForbidden},
+        {"/slow", "", http.StatusInternalServerError},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest("GET", "http://example.com"+v.path, nil)
+        req.Header.Set("Connection", "Upgrade")
+        req.Header.Set("Upgrade", "websocket")
+        req.Header.Set("Sec-WebSocket-Version", "13")
+        req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+        req.Header.Set("Origin", v.origin)
+        w := httptest.NewRecorder()
+        m.ServeHTTP(w, req)
+        if w.Code != v.code {
+            t.Errorf("%s: expected %d got %d", v.path, v.code, w.Code)
+        }
+    }
+}
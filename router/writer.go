@@ -0,0 +1,91 @@
+package router
+
+import "io"
+import "net"
+import "bufio"
+import "net/http"
+
+// responseWriter records the status and size of a response while keeping
+// http.Flusher, its FlushError, http.Hijacker and io.ReaderFrom of the wrapped
+// http.ResponseWriter reachable.
+type responseWriter struct {
+    http.ResponseWriter
+    status int
+    size   int64
+}
+
+func newResponseWriter(response http.ResponseWriter) *responseWriter {
+    return &responseWriter{ResponseWriter: response}
+}
+
+// Status returns the status sent to the client, 200 when the handler did not
+// set one.
+func (writer *responseWriter) Status() int {
+    if writer.status == 0 {
+        return http.StatusOK
+    }
+    return writer.status
+}
+
+// Size returns the number of body bytes written.
+func (writer *responseWriter) Size() int64 {
+    return writer.size
+}
+
+func (writer *responseWriter) WriteHeader(status int) {
+    if writer.status == 0 && status >= http.StatusOK {
+        writer.status = status
+    }
+    writer.ResponseWriter.WriteHeader(status)
+}
+
+func (writer *responseWriter) Write(data []byte) (int, error) {
+    if writer.status == 0 {
+        writer.status = http.StatusOK
+    }
+    size, exception := writer.ResponseWriter.Write(data)
+    writer.size += int64(size)
+    return size, exception
+}
+
+func (writer *responseWriter) ReadFrom(reader io.Reader) (int64, error) {
+    if writer.status == 0 {
+        writer.status = http.StatusOK
+    }
+    var size int64
+    var exception error
+    if from, ok := writer.ResponseWriter.(io.ReaderFrom); ok {
+        size, exception = from.ReadFrom(reader)
+    } else {
+        size, exception = io.Copy(writer.ResponseWriter, reader)
+    }
+    writer.size += size
+    return size, exception
+}
+
+func (writer *responseWriter) Flush() {
+    writer.FlushError()
+}
+
+// FlushError flushes the wrapped http.ResponseWriter, reporting when it
+// cannot be flushed so that http.ResponseController sees it.
+func (writer *responseWriter) FlushError() error {
+    if writer.status == 0 {
+        writer.status = http.StatusOK
+    }
+    return http.NewResponseController(writer.ResponseWriter).Flush()
+}
+
+// Hijack records a hijacked connection as switching protocols.
+func (writer *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    conn, buffer, exception := http.NewResponseController(writer.ResponseWriter).Hijack()
+    if exception == nil && writer.status == 0 {
+        writer.status = http.StatusSwitchingProtocols
+    }
+    return conn, buffer, exception
+}
+
+// Unwrap lets http.ResponseController reach the wrapped http.ResponseWriter.
+func (writer *responseWriter) Unwrap() http.ResponseWriter {
+    return writer.ResponseWriter
+}
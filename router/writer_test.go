@@ -0,0 +1,50 @@
+package router
+
+import (
+    "bufio"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestResponseWriter(t *testing.T) {
+    recorder := httptest.NewRecorder()
+    writer := newResponseWriter(recorder)
+    if writer.Status() != http.StatusOK {
+        t.Errorf("expected %d got %d", http.StatusOK, writer.Status())
+    }
+    writer.WriteHeader(http.StatusAccepted)
+    _, _ = writer.Write([]byte("hello "))
+    _, _ = writer.ReadFrom(strings.NewReader("world"))
+    writer.Flush()
+    if writer.Status() != http.StatusAccepted || writer.Size() != 11 {
+        t.Errorf("expected 202 11 got %d %d", writer.Status(), writer.Size())
+    }
+    if !recorder.Flushed || recorder.Body.String() != "hello world" {
+        t.Errorf("unexpected recorder state %v %s", recorder.Flushed, recorder.Body)
+    }
+}
+
+func TestResponseWriter_hijack(t *testing.T) {
+    ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+        conn, rw, err := newResponseWriter(w).Hijack()
+        if err != nil {
+            t.Error(err)
+            return
+        }
+        defer conn.Close()
+        _, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\n\r\nhijacked")
+        _ = rw.Flush()
+    }))
+    defer ts.Close()
+    resp, err := http.Get(ts.URL)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer resp.Body.Close()
+    line, _ := bufio.NewReader(resp.Body).ReadString(0)
+    if line != "hijacked" {
+        t.Errorf("expected hijacked got %s", line)
+    }
+}
@@ -0,0 +1,385 @@
+package ws
+
+import "io"
+import "net"
+import "sync"
+import "time"
+import "bufio"
+import "errors"
+import "strconv"
+import "unicode/utf8"
+import "encoding/binary"
+
+// MessageType is the opcode of a data message.
+type MessageType int
+
+const (
+    TextMessage   MessageType = 1
+    BinaryMessage MessageType = 2
+)
+
+const (
+    opContinuation = 0x0
+    opText         = 0x1
+    opBinary       = 0x2
+    opClose        = 0x8
+    opPing         = 0x9
+    opPong         = 0xa
+)
+
+// Close codes defined by RFC 6455.
+const (
+    CloseNormal             = 1000
+    CloseGoingAway          = 1001
+    CloseProtocolError      = 1002
+    CloseUnsupportedData    = 1003
+    CloseNoStatus           = 1005
+    CloseAbnormal           = 1006
+    CloseInvalidPayload     = 1007
+    ClosePolicyViolation    = 1008
+    CloseMessageTooBig      = 1009
+    CloseMandatoryExtension = 1010
+    CloseInternalError      = 1011
+)
+
+// ErrorClosed is returned when writing on a Conn whose close frame was sent.
+var ErrorClosed = errors.New("websocket: connection closed")
+
+// CloseError is returned by ReadMessage once the connection is closed,
+// either by a close frame of the peer or because the peer broke the
+// protocol, in which case Code is the code sent to the peer.
+type CloseError struct {
+    Code   int
+    Reason string
+}
+
+func (exception *CloseError) Error() string {
+    if exception.Reason == "" {
+        return "websocket: close " + strconv.Itoa(exception.Code)
+    }
+    return "websocket: close " + strconv.Itoa(exception.Code) + " " + exception.Reason
+}
+
+// Conn is a server side WebSocket connection. Reads must come from a single
+// goroutine, writes may come from several.
+type Conn struct {
+    conn        net.Conn
+    reader      *bufio.Reader
+    subprotocol string
+    compress    bool
+    limit       int64
+    fragment    int
+    pong        func(data []byte)
+    lock        sync.Mutex
+    closeSent   bool
+    once        sync.Once
+}
+
+func newConn(conn net.Conn, reader *bufio.Reader, subprotocol string, compress bool, limit int64, fragment int) *Conn {
+    return &Conn{conn: conn, reader: reader, subprotocol: subprotocol, compress: compress, limit: limit, fragment: fragment}
+}
+
+// Subprotocol returns the negotiated subprotocol, empty when there is none.
+func (conn *Conn) Subprotocol() string {
+    return conn.subprotocol
+}
+
+// Compressed reports whether permessage-deflate was negotiated.
+func (conn *Conn) Compressed() bool {
+    return conn.compress
+}
+
+// RemoteAddr returns the address of the peer.
+func (conn *Conn) RemoteAddr() net.Addr {
+    return conn.conn.RemoteAddr()
+}
+
+// SetReadDeadline sets the deadline of the next reads, see net.Conn.
+func (conn *Conn) SetReadDeadline(deadline time.Time) error {
+    return conn.conn.SetReadDeadline(deadline)
+}
+
+// SetWriteDeadline sets the deadline of the next writes, see net.Conn.
+func (conn *Conn) SetWriteDeadline(deadline time.Time) error {
+    return conn.conn.SetWriteDeadline(deadline)
+}
+
+// SetPongHandler sets the function called from ReadMessage with the payload
+// of every pong received.
+func (conn *Conn) SetPongHandler(handler func(data []byte)) {
+    conn.pong = handler
+}
+
+// frame is a single WebSocket frame read from the peer.
+type frame struct {
+    final    bool
+    compress bool
+    opcode   byte
+    payload  []byte
+}
+
+// readFrame reads a frame, failing the connection when it breaks the
+// protocol.
+func (conn *Conn) readFrame() (frame, error) {
+    var head [14]byte
+    if _, exception := io.ReadFull(conn.reader, head[:2]); exception != nil {
+        return frame{}, exception
+    }
+    value := frame{final: head[0]&0x80 != 0, compress: head[0]&0x40 != 0, opcode: head[0] & 0x0f}
+    control := value.opcode&0x8 != 0
+    switch {
+    case head[0]&0x30 != 0:
+        return value, conn.fail(CloseProtocolError, "reserved bits set")
+    case value.compress && (!conn.compress || control):
+        return value, conn.fail(CloseProtocolError, "unexpected compressed frame")
+    case head[1]&0x80 == 0:
+        return value, conn.fail(CloseProtocolError, "client frame is not masked")
+    case control && !value.final:
+        return value, conn.fail(CloseProtocolError, "fragmented control frame")
+    }
+    length := uint64(head[1] & 0x7f)
+    switch length {
+    case 126:
+        if _, exception := io.ReadFull(conn.reader, head[2:4]); exception != nil {
+            return value, exception
+        }
+        length = uint64(binary.BigEndian.Uint16(head[2:4]))
+    case 127:
+        if _, exception := io.ReadFull(conn.reader, head[2:10]); exception != nil {
+            return value, exception
+        }
+        length = binary.BigEndian.Uint64(head[2:10])
+    }
+    if control && length > 125 {
+        return value, conn.fail(CloseProtocolError, "control frame too long")
+    }
+    if length > uint64(conn.limit) {
+        return value, conn.fail(CloseMessageTooBig, "message too big")
+    }
+    var mask [4]byte
+    if _, exception := io.ReadFull(conn.reader, mask[:]); exception != nil {
+        return value, exception
+    }
+    value.payload = make([]byte, length)
+    if _, exception := io.ReadFull(conn.reader, value.payload); exception != nil {
+        return value, exception
+    }
+    for index := range value.payload {
+        value.payload[index] ^= mask[index&3]
+    }
+    return value, nil
+}
+
+// ReadMessage returns the next data message, reassembling fragments and
+// decompressing it. Pings are answered and close frames acknowledged, the
+// latter ending the connection with a *CloseError.
+func (conn *Conn) ReadMessage() (MessageType, []byte, error) {
+    var kind MessageType
+    var compressed bool
+    var message []byte
+    for {
+        value, exception := conn.readFrame()
+        if exception != nil {
+            conn.closeConn()
+            return 0, nil, exception
+        }
+        switch value.opcode {
+        case opPing:
+            if exception = conn.writeFrame(opPong, false, value.payload); exception != nil && !errors.Is(exception, ErrorClosed) {
+                return 0, nil, exception
+            }
+            continue
+        case opPong:
+            if conn.pong != nil {
+                conn.pong(value.payload)
+            }
+            continue
+        case opClose:
+            return 0, nil, conn.receiveClose(value.payload)
+        case opText, opBinary:
+            if kind != 0 {
+                return 0, nil, conn.fail(CloseProtocolError, "expected a continuation frame")
+            }
+            kind, compressed, message = MessageType(value.opcode), value.compress, value.payload
+        case opContinuation:
+            if kind == 0 {
+                return 0, nil, conn.fail(CloseProtocolError, "unexpected continuation frame")
+            }
+            if value.compress {
+                return 0, nil, conn.fail(CloseProtocolError, "compressed continuation frame")
+            }
+            if int64(len(message)+len(value.payload)) > conn.limit {
+                return 0, nil, conn.fail(CloseMessageTooBig, "message too big")
+            }
+            message = append(message, value.payload...)
+        default:
+            return 0, nil, conn.fail(CloseProtocolError, "unknown opcode "+strconv.Itoa(int(value.opcode)))
+        }
+        if !value.final {
+            continue
+        }
+        if compressed {
+            if message, exception = inflate(message, conn.limit); exception != nil {
+                if errors.Is(exception, errorTooBig) {
+                    return 0, nil, conn.fail(CloseMessageTooBig, "message too big")
+                }
+                return 0, nil, conn.fail(CloseInvalidPayload, "invalid compressed data")
+            }
+        }
+        if kind == TextMessage && !utf8.Valid(message) {
+            return 0, nil, conn.fail(CloseInvalidPayload, "invalid utf-8 text")
+        }
+        return kind, message, nil
+    }
+}
+
+// receiveClose acknowledges the close frame of the peer carrying payload.
+func (conn *Conn) receiveClose(payload []byte) error {
+    result := &CloseError{Code: CloseNoStatus}
+    switch {
+    case len(payload) == 1:
+        return conn.fail(CloseProtocolError, "invalid close payload")
+    case len(payload) >= 2:
+        result.Code = int(binary.BigEndian.Uint16(payload))
+        result.Reason = string(payload[2:])
+        if !validCloseCode(result.Code) {
+            return conn.fail(CloseProtocolError, "invalid close code")
+        }
+        if !utf8.ValidString(result.Reason) {
+            return conn.fail(CloseInvalidPayload, "invalid utf-8 close reason")
+        }
+    }
+    var reply []byte
+    if result.Code != CloseNoStatus {
+        reply = binary.BigEndian.AppendUint16(nil, uint16(result.Code))
+    }
+    conn.writeFrame(opClose, false, reply)
+    conn.closeConn()
+    return result
+}
+
+// validCloseCode reports whether a peer may send code in a close frame.
+func validCloseCode(code int) bool {
+    switch {
+    case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011:
+        return true
+    case code >= 3000 && code <= 4999:
+        return true
+    }
+    return false
+}
+
+// fail closes the connection with code after the peer broke the protocol.
+func (conn *Conn) fail(code int, reason string) error {
+    conn.writeFrame(opClose, false, closePayload(code, reason))
+    conn.closeConn()
+    return &CloseError{Code: code, Reason: reason}
+}
+
+// WriteMessage sends data as a single message, compressed when
+// permessage-deflate was negotiated and split into frames of FragmentSize.
+func (conn *Conn) WriteMessage(kind MessageType, data []byte) error {
+    if kind != TextMessage && kind != BinaryMessage {
+        return errors.New("websocket: invalid message type")
+    }
+    compressed := false
+    if conn.compress && len(data) > 0 {
+        value, exception := deflate(data)
+        if exception != nil {
+            return exception
+        }
+        data, compressed = value, true
+    }
+    conn.lock.Lock()
+    defer conn.lock.Unlock()
+    if conn.closeSent {
+        return ErrorClosed
+    }
+    opcode := byte(kind)
+    for {
+        size := len(data)
+        if conn.fragment > 0 && size > conn.fragment {
+            size = conn.fragment
+        }
+        final := size == len(data)
+        if exception := conn.writeRaw(opcode, final, compressed, data[:size]); exception != nil {
+            return exception
+        }
+        if final {
+            return nil
+        }
+        data, opcode, compressed = data[size:], opContinuation, false
+    }
+}
+
+// Ping sends a ping carrying data, at most 125 bytes.
+func (conn *Conn) Ping(data []byte) error {
+    if len(data) > 125 {
+        return errors.New("websocket: ping payload too long")
+    }
+    return conn.writeFrame(opPing, false, data)
+}
+
+// Close sends a close frame with code and reason and closes the connection.
+// Closing an already closed Conn does nothing.
+func (conn *Conn) Close(code int, reason string) error {
+    exception := conn.writeFrame(opClose, false, closePayload(code, reason))
+    conn.closeConn()
+    if errors.Is(exception, ErrorClosed) {
+        return nil
+    }
+    return exception
+}
+
+func closePayload(code int, reason string) []byte {
+    if code == CloseNoStatus {
+        return nil
+    }
+    if len(reason) > 123 {
+        reason = reason[:123]
+    }
+    return append(binary.BigEndian.AppendUint16(nil, uint16(code)), reason...)
+}
+
+// writeFrame sends a single final frame, nothing once a close frame was sent.
+func (conn *Conn) writeFrame(opcode byte, compressed bool, payload []byte) error {
+    conn.lock.Lock()
+    defer conn.lock.Unlock()
+    if conn.closeSent {
+        return ErrorClosed
+    }
+    if opcode == opClose {
+        conn.closeSent = true
+    }
+    return conn.writeRaw(opcode, true, compressed, payload)
+}
+
+// writeRaw sends an unmasked frame, the caller holds the lock.
+func (conn *Conn) writeRaw(opcode byte, final, compressed bool, payload []byte) error {
+    head := make([]byte, 2, 10+len(payload))
+    head[0] = opcode
+    if final {
+        head[0] |= 0x80
+    }
+    if compressed {
+        head[0] |= 0x40
+    }
+    switch length := len(payload); {
+    case length < 126:
+        head[1] = byte(length)
+    case length <= 0xffff:
+        head[1] = 126
+        head = binary.BigEndian.AppendUint16(head, uint16(length))
+    default:
+        head[1] = 127
+        head = binary.BigEndian.AppendUint64(head, uint64(length))
+    }
+    _, exception := conn.conn.Write(append(head, payload...))
+    return exception
+}
+
+func (conn *Conn) closeConn() {
+    conn.once.Do(func() {
+        conn.conn.Close()
+    })
+}
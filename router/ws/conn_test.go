@@ -0,0 +1,250 @@
+package ws
+
+import (
+    "bufio"
+    "bytes"
+    "compress/flate"
+    "encoding/binary"
+    "errors"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+// client is the peer side of a test connection.
+type client struct {
+    t      *testing.T
+    conn   net.Conn
+    reader *bufio.Reader
+    header http.Header
+}
+
+func dial(t *testing.T, server *httptest.Server, header map[string]string) *client {
+    t.Helper()
+    conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    request := "GET /ws HTTP/1.1\r\nHost: " + strings.TrimPrefix(server.URL, "http://") +
+        "\r\nConnection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"
+    for key, value := range header {
+        request += key + ": " + value + "\r\n"
+    }
+    if _, err = conn.Write([]byte(request + "\r\n")); err != nil {
+        t.Fatal(err)
+    }
+    reader := bufio.NewReader(conn)
+    response, err := http.ReadResponse(reader, nil)
+    if err != nil || response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+        t.Fatalf("handshake failed %v %v", response, err)
+    }
+    conn.SetDeadline(time.Now().Add(5 * time.Second))
+    return &client{t: t, conn: conn, reader: reader, header: response.Header}
+}
+
+func (c *client) write(head byte, payload []byte, masked bool) {
+    c.t.Helper()
+    data := []byte{head, 0}
+    switch {
+    case len(payload) < 126:
+        data[1] = byte(len(payload))
+    case len(payload) <= 0xffff:
+        data[1] = 126
+        data = binary.BigEndian.AppendUint16(data, uint16(len(payload)))
+    default:
+        data[1] = 127
+        data = binary.BigEndian.AppendUint64(data, uint64(len(payload)))
+    }
+    body := append([]byte(nil), payload...)
+    if masked {
+        data[1] |= 0x80
+        mask := []byte{1, 2, 3, 4}
+        data = append(data, mask...)
+        for index := range body {
+            body[index] ^= mask[index&3]
+        }
+    }
+    if _, err := c.conn.Write(append(data, body...)); err != nil {
+        c.t.Fatal(err)
+    }
+}
+
+func (c *client) read() (byte, []byte) {
+    c.t.Helper()
+    var head [2]byte
+    if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+        c.t.Fatal(err)
+    }
+    length := uint64(head[1] & 0x7f)
+    switch length {
+    case 126:
+        var size [2]byte
+        io.ReadFull(c.reader, size[:])
+        length = uint64(binary.BigEndian.Uint16(size[:]))
+    case 127:
+        var size [8]byte
+        io.ReadFull(c.reader, size[:])
+        length = binary.BigEndian.Uint64(size[:])
+    }
+    payload := make([]byte, length)
+    if _, err := io.ReadFull(c.reader, payload); err != nil {
+        c.t.Fatal(err)
+    }
+    return head[0], payload
+}
+
+func (c *client) expectClose(code int) {
+    c.t.Helper()
+    head, payload := c.read()
+    if head != 0x80|opClose || len(payload) < 2 || int(binary.BigEndian.Uint16(payload)) != code {
+        c.t.Errorf("expected close %d got %x %q", code, head, payload)
+    }
+}
+
+func echo(upgrader *Upgrader, result chan<- error) *httptest.Server {
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        conn, err := upgrader.Upgrade(w, r)
+        if err != nil {
+            result <- err
+            return
+        }
+        for {
+            kind, data, err := conn.ReadMessage()
+            if err != nil {
+                result <- err
+                return
+            }
+            if err = conn.WriteMessage(kind, data); err != nil {
+                result <- err
+                return
+            }
+        }
+    }))
+}
+
+func TestConn(t *testing.T) {
+    result := make(chan error, 1)
+    server := echo(&Upgrader{}, result)
+    defer server.Close()
+    c := dial(t, server, nil)
+
+    c.write(0x80|opText, []byte("hello"), true)
+    if head, payload := c.read(); head != 0x80|opText || string(payload) != "hello" {
+        t.Errorf("unexpected echo %x %q", head, payload)
+    }
+    large := bytes.Repeat([]byte{7}, 70000)
+    c.write(0x80|opBinary, large, true)
+    if head, payload := c.read(); head != 0x80|opBinary || !bytes.Equal(payload, large) {
+        t.Errorf("unexpected echo %x of %d bytes", head, len(payload))
+    }
+    c.write(opText, []byte("frag"), true)
+    c.write(0x80|opPing, []byte("beat"), true)
+    c.write(0x80|opContinuation, []byte("mented"), true)
+    if head, payload := c.read(); head != 0x80|opPong || string(payload) != "beat" {
+        t.Errorf("expected pong got %x %q", head, payload)
+    }
+    if head, payload := c.read(); head != 0x80|opText || string(payload) != "fragmented" {
+        t.Errorf("unexpected echo %x %q", head, payload)
+    }
+    c.write(0x80|opClose, closePayload(CloseGoingAway, "bye"), true)
+    c.expectClose(CloseGoingAway)
+    var closed *CloseError
+    if err := <-result; !errors.As(err, &closed) || closed.Code != CloseGoingAway || closed.Reason != "bye" {
+        t.Errorf("expected close 1001 bye got %v", err)
+    }
+}
+
+func TestConn_ProtocolError(t *testing.T) {
+    sample := []struct {
+        name    string
+        head    byte
+        payload []byte
+        masked  bool
+        code    int
+    }{
+        {"unmasked", 0x80 | opText, []byte("hello"), false, CloseProtocolError},
+        {"reserved", 0x80 | 0x20 | opText, []byte("hello"), true, CloseProtocolError},
+        {"compressed", 0x80 | 0x40 | opText, []byte("hello"), true, CloseProtocolError},
+        {"opcode", 0x80 | 0x3, nil, true, CloseProtocolError},
+        {"continuation", 0x80 | opContinuation, []byte("hello"), true, CloseProtocolError},
+        {"fragmented ping", opPing, nil, true, CloseProtocolError},
+        {"long ping", 0x80 | opPing, make([]byte, 126), true, CloseProtocolError},
+        {"utf-8", 0x80 | opText, []byte{0xff, 0xfe}, true, CloseInvalidPayload},
+        {"too big", 0x80 | opBinary, make([]byte, 65), true, CloseMessageTooBig},
+        {"close code", 0x80 | opClose, []byte{0x03, 0xed}, true, CloseProtocolError},
+        {"close payload", 0x80 | opClose, []byte{3}, true, CloseProtocolError},
+    }
+    for _, v := range sample {
+        result := make(chan error, 1)
+        server := echo(&Upgrader{ReadLimit: 64}, result)
+        c := dial(t, server, nil)
+        c.write(v.head, v.payload, v.masked)
+        c.expectClose(v.code)
+        var closed *CloseError
+        if err := <-result; !errors.As(err, &closed) || closed.Code != v.code {
+            t.Errorf("%s: expected close %d got %v", v.name, v.code, err)
+        }
+        server.Close()
+    }
+}
+
+func TestConn_Compression(t *testing.T) {
+    result := make(chan error, 1)
+    server := echo(&Upgrader{Compression: true, FragmentSize: 16}, result)
+    defer server.Close()
+    c := dial(t, server, map[string]string{"Sec-WebSocket-Extensions": "permessage-deflate; client_max_window_bits"})
+    if value := c.header.Get("Sec-WebSocket-Extensions"); value != "permessage-deflate; server_no_context_takeover; client_no_context_takeover" {
+        t.Fatalf("unexpected extension %q", value)
+    }
+    message := strings.Repeat("compressed ", 50)
+    var buffer bytes.Buffer
+    writer, _ := flate.NewWriter(&buffer, flate.BestCompression)
+    writer.Write([]byte(message))
+    writer.Flush()
+    c.write(0x80|0x40|opText, bytes.TrimSuffix(buffer.Bytes(), []byte{0, 0, 0xff, 0xff}), true)
+
+    head, data := c.read()
+    if head != 0x40|opText || len(data) > 16 {
+        t.Fatalf("expected a compressed first fragment got %x of %d bytes", head, len(data))
+    }
+    for head&0x80 == 0 {
+        var payload []byte
+        head, payload = c.read()
+        if head&0x7f != opContinuation || len(payload) > 16 {
+            t.Fatalf("expected a continuation frame got %x of %d bytes", head, len(payload))
+        }
+        data = append(data, payload...)
+    }
+    if value, err := inflate(data, 1<<20); err != nil || string(value) != message {
+        t.Errorf("unexpected echo %q %v", value, err)
+    }
+    c.write(0x80|opClose, closePayload(CloseNormal, ""), true)
+    c.expectClose(CloseNormal)
+    <-result
+}
+
+func TestConn_Close(t *testing.T) {
+    done := make(chan error, 1)
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        conn, err := (&Upgrader{}).Upgrade(w, r)
+        if err != nil {
+            done <- err
+            return
+        }
+        conn.Close(ClosePolicyViolation, "go away")
+        done <- conn.WriteMessage(TextMessage, []byte("late"))
+    }))
+    defer server.Close()
+    c := dial(t, server, nil)
+    head, payload := c.read()
+    if head != 0x80|opClose || binary.BigEndian.Uint16(payload) != ClosePolicyViolation || string(payload[2:]) != "go away" {
+        t.Errorf("unexpected close %x %q", head, payload)
+    }
+    if err := <-done; !errors.Is(err, ErrorClosed) {
+        t.Errorf("expected %v got %v", ErrorClosed, err)
+    }
+}
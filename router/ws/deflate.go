@@ -0,0 +1,47 @@
+package ws
+
+import "io"
+import "sync"
+import "bytes"
+import "errors"
+import "compress/flate"
+
+// tail ends a permessage-deflate payload: the sync flush marker stripped by
+// the sender, followed by an empty final block so the reader meets EOF.
+const tail = "\x00\x00\xff\xff\x01\x00\x00\xff\xff"
+
+var errorTooBig = errors.New("websocket: message too big")
+
+var compressor = sync.Pool{New: func() any {
+    writer, _ := flate.NewWriter(nil, flate.DefaultCompression)
+    return writer
+}}
+
+// deflate compresses a message on its own, without context takeover.
+func deflate(data []byte) ([]byte, error) {
+    var buffer bytes.Buffer
+    writer := compressor.Get().(*flate.Writer)
+    defer compressor.Put(writer)
+    writer.Reset(&buffer)
+    if _, exception := writer.Write(data); exception != nil {
+        return nil, exception
+    }
+    if exception := writer.Flush(); exception != nil {
+        return nil, exception
+    }
+    return bytes.TrimSuffix(buffer.Bytes(), []byte(tail[:4])), nil
+}
+
+// inflate decompresses a message of at most limit bytes.
+func inflate(data []byte, limit int64) ([]byte, error) {
+    reader := flate.NewReader(io.MultiReader(bytes.NewReader(data), bytes.NewReader([]byte(tail))))
+    defer reader.Close()
+    result, exception := io.ReadAll(io.LimitReader(reader, limit+1))
+    if exception != nil {
+        return nil, exception
+    }
+    if int64(len(result)) > limit {
+        return nil, errorTooBig
+    }
+    return result, nil
+}
@@ -0,0 +1,184 @@
+// Package ws implements RFC 6455 WebSocket connections on top of net/http,
+// with the permessage-deflate extension of RFC 7692.
+package ws
+
+import "errors"
+import "net/url"
+import "strings"
+import "net/http"
+import "crypto/sha1"
+import "encoding/base64"
+
+// guid is appended to Sec-WebSocket-Key to derive Sec-WebSocket-Accept.
+const guid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// DefaultReadLimit is the largest message a Conn reads unless
+// Upgrader.ReadLimit says otherwise.
+var DefaultReadLimit int64 = 16 << 20
+
+// Upgrader turns http requests into WebSocket connections.
+type Upgrader struct {
+    // CheckOrigin accepts the Origin of request, it defaults to accepting
+    // requests without Origin and those whose Origin host is the request host.
+    CheckOrigin func(request *http.Request) bool
+    // Subprotocol lists the subprotocols supported, by preference.
+    Subprotocol []string
+    // Compression negotiates permessage-deflate when the client offers it.
+    Compression bool
+    // ReadLimit is the largest message read, it defaults to DefaultReadLimit.
+    ReadLimit int64
+    // FragmentSize splits written messages into frames of at most this many
+    // bytes, 0 sends every message in a single frame.
+    FragmentSize int
+}
+
+// Upgrade completes the WebSocket handshake of request and hijacks its
+// connection. On failure the matching error response is written: 405 for
+// other methods than GET, 400 for malformed handshakes, 426 for unsupported
+// versions, 403 for rejected origins and 500 when response cannot be
+// hijacked.
+func (upgrader *Upgrader) Upgrade(response http.ResponseWriter, request *http.Request) (*Conn, error) {
+    if request.Method != http.MethodGet {
+        return nil, reject(response, http.StatusMethodNotAllowed, "websocket: method must be GET")
+    }
+    if !headerContains(request.Header, "Connection", "upgrade") || !headerContains(request.Header, "Upgrade", "websocket") {
+        return nil, reject(response, http.StatusBadRequest, "websocket: request is not an upgrade to websocket")
+    }
+    if request.Header.Get("Sec-WebSocket-Version") != "13" {
+        response.Header().Set("Sec-WebSocket-Version", "13")
+        return nil, reject(response, http.StatusUpgradeRequired, "websocket: unsupported version")
+    }
+    key := strings.TrimSpace(request.Header.Get("Sec-WebSocket-Key"))
+    if data, exception := base64.StdEncoding.DecodeString(key); exception != nil || len(data) != 16 {
+        return nil, reject(response, http.StatusBadRequest, "websocket: invalid Sec-WebSocket-Key")
+    }
+    check := upgrader.CheckOrigin
+    if check == nil {
+        check = sameOrigin
+    }
+    if !check(request) {
+        return nil, reject(response, http.StatusForbidden, "websocket: origin not allowed")
+    }
+    subprotocol := upgrader.selectSubprotocol(request)
+    compress := upgrader.Compression && offerDeflate(request.Header)
+
+    connection, buffer, exception := http.NewResponseController(response).Hijack()
+    if exception != nil {
+        return nil, reject(response, http.StatusInternalServerError, "websocket: connection cannot be hijacked")
+    }
+    var builder strings.Builder
+    builder.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: ")
+    builder.WriteString(acceptKey(key))
+    builder.WriteString("\r\n")
+    if subprotocol != "" {
+        builder.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+    }
+    if compress {
+        builder.WriteString("Sec-WebSocket-Extensions: permessage-deflate; server_no_context_takeover; client_no_context_takeover\r\n")
+    }
+    for name, list := range response.Header() {
+        for _, value := range list {
+            builder.WriteString(name + ": " + strings.NewReplacer("\r", "", "\n", "").Replace(value) + "\r\n")
+        }
+    }
+    builder.WriteString("\r\n")
+    if _, exception = connection.Write([]byte(builder.String())); exception != nil {
+        connection.Close()
+        return nil, exception
+    }
+    limit := upgrader.ReadLimit
+    if limit <= 0 {
+        limit = DefaultReadLimit
+    }
+    return newConn(connection, buffer.Reader, subprotocol, compress, limit, upgrader.FragmentSize), nil
+}
+
+// reject writes an error response for a failed handshake.
+func reject(response http.ResponseWriter, status int, message string) error {
+    http.Error(response, message, status)
+    return errors.New(message)
+}
+
+// acceptKey returns the Sec-WebSocket-Accept value of key.
+func acceptKey(key string) string {
+    digest := sha1.Sum([]byte(key + guid))
+    return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// headerContains reports whether the comma separated header name lists
+// token, ignoring case.
+func headerContains(header http.Header, name, token string) bool {
+    for _, line := range header.Values(name) {
+        for _, item := range strings.Split(line, ",") {
+            if strings.EqualFold(strings.TrimSpace(item), token) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// sameOrigin accepts requests without Origin, as sent by non browser
+// clients, and requests whose Origin host is the host requested.
+func sameOrigin(request *http.Request) bool {
+    origin := request.Header.Get("Origin")
+    if origin == "" {
+        return true
+    }
+    value, exception := url.Parse(origin)
+    if exception != nil {
+        return false
+    }
+    return strings.EqualFold(value.Host, request.Host)
+}
+
+func (upgrader *Upgrader) selectSubprotocol(request *http.Request) string {
+    var offer []string
+    for _, line := range request.Header.Values("Sec-WebSocket-Protocol") {
+        for _, item := range strings.Split(line, ",") {
+            offer = append(offer, strings.TrimSpace(item))
+        }
+    }
+    for _, value := range upgrader.Subprotocol {
+        for _, item := range offer {
+            if item == value {
+                return value
+            }
+        }
+    }
+    return ""
+}
+
+// offerDeflate reports whether the client offers a permessage-deflate
+// configuration the server can accept. The server always answers with
+// server_no_context_takeover and client_no_context_takeover so that every
+// message is compressed on its own, and it cannot honor a reduced
+// server_max_window_bits.
+func offerDeflate(header http.Header) bool {
+    for _, line := range header.Values("Sec-WebSocket-Extensions") {
+        for _, offer := range strings.Split(line, ",") {
+            list := strings.Split(offer, ";")
+            if strings.TrimSpace(list[0]) != "permessage-deflate" {
+                continue
+            }
+            accepted := true
+            for _, item := range list[1:] {
+                name, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+                switch name {
+                case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+                case "server_max_window_bits":
+                    accepted = strings.Trim(value, `"`) == "15"
+                default:
+                    accepted = false
+                }
+                if !accepted {
+                    break
+                }
+            }
+            if accepted {
+                return true
+            }
+        }
+    }
+    return false
+}
@@ -0,0 +1,75 @@
+package ws
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestUpgrader_Reject(t *testing.T) {
+    sample := []struct {
+        method string
+        header map[string]string
+        code   int
+    }{
+        {"POST", nil, http.StatusMethodNotAllowed},
+        {"GET", map[string]string{"Upgrade": ""}, http.StatusBadRequest},
+        {"GET", map[string]string{"Connection": "close"}, http.StatusBadRequest},
+        {"GET", map[string]string{"Sec-WebSocket-Version": "8"}, http.StatusUpgradeRequired},
+        {"GET", map[string]string{"Sec-WebSocket-Key": "short"}, http.StatusBadRequest},
+        {"GET", map[string]string{"Origin": "https://evil.example.com"}, http.StatusForbidden},
+        {"GET", map[string]string{"Origin": "http://example.com"}, http.StatusInternalServerError},
+    }
+    for _, v := range sample {
+        req := httptest.NewRequest(v.method, "http://example.com/ws", nil)
+        req.Header.Set("Connection", "keep-alive, Upgrade")
+        req.Header.Set("Upgrade", "websocket")
+        req.Header.Set("Sec-WebSocket-Version", "13")
+        req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+        for key, value := range v.header {
+            req.Header.Set(key, value)
+        }
+        w := httptest.NewRecorder()
+        if _, err := (&Upgrader{}).Upgrade(w, req); err == nil || w.Code != v.code {
+            t.Errorf("%s %v: expected %d got %d %v", v.method, v.header, v.code, w.Code, err)
+        }
+    }
+}
+
+func TestAcceptKey(t *testing.T) {
+    if value := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); value != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+        t.Errorf("unexpected accept key %s", value)
+    }
+}
+
+func TestOfferDeflate(t *testing.T) {
+    sample := []struct {
+        offer  string
+        accept bool
+    }{
+        {"permessage-deflate", true},
+        {"permessage-deflate; client_max_window_bits", true},
+        {"permessage-deflate; server_max_window_bits=10, permessage-deflate", true},
+        {"permessage-deflate; server_max_window_bits=10", false},
+        {"permessage-deflate; unknown", false},
+        {"x-webkit-deflate-frame", false},
+        {"", false},
+    }
+    for _, v := range sample {
+        header := http.Header{"Sec-Websocket-Extensions": {v.offer}}
+        if offerDeflate(header) != v.accept {
+            t.Errorf("%q: expected %v", v.offer, v.accept)
+        }
+    }
+}
+
+func TestUpgrader_Subprotocol(t *testing.T) {
+    req := httptest.NewRequest("GET", "/ws", nil)
+    req.Header.Set("Sec-WebSocket-Protocol", "chat, superchat")
+    if value := (&Upgrader{Subprotocol: []string{"superchat", "chat"}}).selectSubprotocol(req); value != "superchat" {
+        t.Errorf("expected superchat got %q", value)
+    }
+    if value := (&Upgrader{Subprotocol: []string{"mqtt"}}).selectSubprotocol(req); value != "" {
+        t.Errorf("expected no subprotocol got %q", value)
+    }
+}